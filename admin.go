@@ -0,0 +1,163 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"umid/blockchain"
+	"umid/storage"
+)
+
+const usage = `usage: umid [command]
+
+Without a command the node is started.
+
+Commands:
+  rollback <height>                  revert confirmed state and remove all blocks above the height
+  snapshot export <file>             write the state at the last confirmed block to an archive and print its digest
+  snapshot import <file> <digest>    load an archive into a new database, the node syncs from its height when started
+
+Only the digest of a snapshot and the signer of its last block are checked, the state is taken as is. Take the
+digest from a trusted source.`
+
+// runCommand executes an administrative command and returns the exit code.
+func runCommand(args []string) int {
+	switch args[0] {
+	case "rollback":
+		return rollback(args[1:])
+	case "snapshot":
+		return snapshot(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+
+		return 2
+	}
+}
+
+func rollback(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, usage)
+
+		return 2
+	}
+
+	height, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil || height == 0 {
+		fmt.Fprintf(os.Stderr, "invalid height %q\n", args[0])
+
+		return 2
+	}
+
+	n, err := storage.NewStorage().RollbackToHeight(uint32(height))
+	if err != nil {
+		log.Println(err.Error())
+
+		return 1
+	}
+
+	log.Printf("%d blocks above %d rolled back\n", n, height)
+
+	return 0
+}
+
+func snapshot(args []string) int {
+	var err error
+
+	switch {
+	case len(args) == 2 && args[0] == "export":
+		err = exportSnapshot(args[1])
+	case len(args) == 3 && args[0] == "import":
+		digest, dErr := hex.DecodeString(args[2])
+		if dErr != nil || len(digest) != sha256.Size {
+			fmt.Fprintf(os.Stderr, "invalid digest %q\n", args[2])
+
+			return 2
+		}
+
+		err = importSnapshot(args[1], digest)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+
+		return 2
+	}
+
+	if err != nil {
+		log.Println(err.Error())
+
+		return 1
+	}
+
+	return 0
+}
+
+// exportSnapshot writes to a temporary file first, so an interrupted export never leaves a truncated archive.
+func exportSnapshot(name string) error {
+	f, err := os.OpenFile(name+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	snp, err := storage.NewStorage().ExportSnapshot(f)
+
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+
+	if err != nil {
+		_ = os.Remove(name + ".tmp")
+
+		return err
+	}
+
+	if err := os.Rename(name+".tmp", name); err != nil {
+		return err
+	}
+
+	log.Printf("snapshot of block %d %x written to %s, digest %x\n", snp.Height, snp.Hash, name, snp.Digest)
+
+	return nil
+}
+
+func importSnapshot(name string, digest []byte) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	db := storage.NewStorage()
+	db.SetSignerValidator(blockchain.NewBlockchain().VerifyPublicKeyAt)
+
+	snp, err := db.ImportSnapshot(f, digest)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("snapshot of block %d %x imported\n", snp.Height, snp.Hash)
+
+	return nil
+}
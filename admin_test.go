@@ -0,0 +1,53 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import "testing"
+
+// TestRunCommandArgs covers the argument errors, which are reported before the database is opened.
+func TestRunCommandArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"unknown command", []string{"start"}},
+		{"rollback without height", []string{"rollback"}},
+		{"rollback with extra argument", []string{"rollback", "10", "20"}},
+		{"rollback to zero", []string{"rollback", "0"}},
+		{"rollback to negative height", []string{"rollback", "-1"}},
+		{"rollback to non-numeric height", []string{"rollback", "ten"}},
+		{"rollback above uint32", []string{"rollback", "4294967296"}},
+		{"snapshot without file", []string{"snapshot", "export"}},
+		{"snapshot with extra argument", []string{"snapshot", "export", "a", "b"}},
+		{"snapshot import without digest", []string{"snapshot", "import", "a"}},
+		{"snapshot import with invalid digest", []string{"snapshot", "import", "a", "xyz"}},
+		{"snapshot import with short digest", []string{"snapshot", "import", "a", "abcd"}},
+		{"unknown snapshot action", []string{"snapshot", "verify", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := runCommand(tt.args); code != 2 {
+				t.Errorf("expected exit code 2, got %d", code)
+			}
+		})
+	}
+}
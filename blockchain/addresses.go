@@ -0,0 +1,138 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+	"umid/umid"
+)
+
+const (
+	defaultTopCacheTTL = time.Minute
+	maxTopCacheEntries = 1024
+)
+
+type topKey struct {
+	filter umid.AddressFilter
+	limit  int
+	offset int
+}
+
+type topEntry struct {
+	addresses []*umid.TopAddress
+	expires   time.Time
+}
+
+// topCache keeps rich-list pages for a short time, the ranking only changes when blocks are confirmed, so a
+// public explorer polling the same pages does not hit the database on every request.
+type topCache struct {
+	sync.Mutex
+	ttl     time.Duration
+	entries map[topKey]topEntry
+}
+
+func newTopCache() *topCache {
+	c := &topCache{
+		ttl:     defaultTopCacheTTL,
+		entries: make(map[topKey]topEntry),
+	}
+
+	if val, ok := os.LookupEnv("TOP_ADDRESSES_CACHE_TTL"); ok {
+		ttl, err := time.ParseDuration(val)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		c.ttl = ttl
+	}
+
+	return c
+}
+
+func (c *topCache) get(k topKey) ([]*umid.TopAddress, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.entries[k]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+
+	return e.addresses, true
+}
+
+func (c *topCache) put(k topKey, a []*umid.TopAddress) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	now := time.Now()
+
+	if len(c.entries) >= maxTopCacheEntries {
+		for key, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, key)
+			}
+		}
+
+		if len(c.entries) >= maxTopCacheEntries {
+			c.entries = make(map[topKey]topEntry)
+		}
+	}
+
+	c.entries[k] = topEntry{addresses: a, expires: now.Add(c.ttl)}
+}
+
+// TopAddresses ...
+func (bc *Blockchain) TopAddresses(f umid.AddressFilter, limit int, offset int) ([]*umid.TopAddress, error) {
+	k := topKey{filter: f, limit: limit, offset: offset}
+
+	if res, ok := bc.topCache.get(k); ok {
+		return res, nil
+	}
+
+	raw, err := bc.storage.TopAddresses(f, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*umid.TopAddress, len(raw))
+
+	for i, a := range raw {
+		res[i] = &umid.TopAddress{
+			Address:   convertAddress(a.Address),
+			Type:      a.Type,
+			Value:     a.Value,
+			Percent:   a.Percent,
+			UpdatedAt: a.UpdatedAt.Unix(),
+		}
+	}
+
+	bc.topCache.put(k, res)
+
+	return res, nil
+}
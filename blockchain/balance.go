@@ -26,12 +26,59 @@ import (
 	"github.com/umitop/libumi"
 )
 
-// Balance ...
-func (bc *Blockchain) Balance(s string) (*umid.Balance, error) {
+// Balance returns the balance at the requested time or block height, by default the current one.
+func (bc *Blockchain) Balance(s string, at umid.BalanceAt) (*umid.Balance, error) {
 	adr, err := libumi.NewAddressFromBech32(s)
 	if err != nil {
 		return nil, err
 	}
 
-	return bc.storage.Balance(adr)
+	if at.Height != nil {
+		blk, err := bc.storage.BlockByHeight(*at.Height)
+		if err != nil {
+			return nil, err
+		}
+
+		// balances only change when a block is confirmed, an unconfirmed one has no balance yet
+		if !blk.Confirmed {
+			return nil, umid.ErrNotFound
+		}
+
+		at.Time = &blk.CreatedAt
+	}
+
+	return bc.storage.Balance(adr, at.Time)
+}
+
+// BalanceHistory ...
+func (bc *Blockchain) BalanceHistory(s string, before *int64, limit int) ([]*umid.BalanceEntry, error) {
+	adr, err := libumi.NewAddressFromBech32(s)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := bc.storage.BalanceHistory(adr, before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*umid.BalanceEntry, len(raw))
+
+	for i, e := range raw {
+		res[i] = &umid.BalanceEntry{
+			ID:        e.ID,
+			TxHeight:  e.TxHeight,
+			UpdatedAt: e.UpdatedAt.Unix(),
+			Delta:     e.Delta,
+			Interest:  e.Interest,
+			Balance:   e.Balance,
+			Percent:   e.Percent,
+		}
+
+		if e.Comment != nil {
+			res[i].Comment = *e.Comment
+		}
+	}
+
+	return res, nil
 }
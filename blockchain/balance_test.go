@@ -0,0 +1,69 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"umid/blockchain"
+	"umid/umid"
+
+	"github.com/umitop/libumi"
+)
+
+type balanceStorage struct {
+	umid.IStorage
+	confirmed uint32
+	at        *time.Time
+}
+
+func (s *balanceStorage) BlockByHeight(height uint32) (*umid.Block2, error) {
+	return &umid.Block2{Height: height, CreatedAt: time.Unix(int64(height), 0), Confirmed: height <= s.confirmed}, nil
+}
+
+func (s *balanceStorage) Balance(_ []byte, at *time.Time) (*umid.Balance, error) {
+	s.at = at
+
+	return &umid.Balance{}, nil
+}
+
+func TestBalanceAtHeight(t *testing.T) {
+	db := &balanceStorage{confirmed: 10}
+	bc := blockchain.NewBlockchain().SetStorage(db)
+	adr := libumi.NewAddress().Bech32()
+
+	height := uint32(10)
+
+	if _, err := bc.Balance(adr, umid.BalanceAt{Height: &height}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if db.at == nil || db.at.Unix() != 10 {
+		t.Errorf("balance should be taken at the block time: got %v", db.at)
+	}
+
+	height = 11
+
+	if _, err := bc.Balance(adr, umid.BalanceAt{Height: &height}); !errors.Is(err, umid.ErrNotFound) {
+		t.Errorf("unconfirmed block: got %v want %v", err, umid.ErrNotFound)
+	}
+}
@@ -22,8 +22,8 @@ package blockchain
 
 import (
 	"context"
-	"encoding/hex"
 	"errors"
+	"log"
 	"sync"
 	"umid/umid"
 )
@@ -35,24 +35,24 @@ var errTooManyRequests = errors.New("too many requests")
 // Blockchain ...
 type Blockchain struct {
 	storage      umid.IStorage
+	keystore     umid.IKeystore
+	wallet       umid.IWallet
 	transaction  chan []byte
-	approvedKeys map[string]struct{}
+	approvedKeys map[string][]signer
+	topCache     *topCache
 }
 
 // NewBlockchain ...
 func NewBlockchain() *Blockchain {
-	keys := []string{
-		"45885c9687d799a4d1f4d786d8639274d293ed024ad7f7a436715d6217c9f72b",
+	keys, err := loadSigners()
+	if err != nil {
+		log.Fatal(err.Error())
 	}
 
 	bc := &Blockchain{
 		transaction:  make(chan []byte, txQueueLen),
-		approvedKeys: make(map[string]struct{}, len(keys)),
-	}
-
-	for _, key := range keys {
-		b, _ := hex.DecodeString(key)
-		bc.approvedKeys[string(b)] = struct{}{}
+		approvedKeys: keys,
+		topCache:     newTopCache(),
 	}
 
 	return bc
@@ -65,6 +65,20 @@ func (bc *Blockchain) SetStorage(db umid.IStorage) *Blockchain {
 	return bc
 }
 
+// SetKeystore ...
+func (bc *Blockchain) SetKeystore(ks umid.IKeystore) *Blockchain {
+	bc.keystore = ks
+
+	return bc
+}
+
+// SetWallet ...
+func (bc *Blockchain) SetWallet(w umid.IWallet) *Blockchain {
+	bc.wallet = w
+
+	return bc
+}
+
 // Worker ...
 func (bc *Blockchain) Worker(ctx context.Context, wg *sync.WaitGroup) {
 	wg.Add(1)
@@ -75,7 +89,9 @@ func (bc *Blockchain) Worker(ctx context.Context, wg *sync.WaitGroup) {
 		case <-ctx.Done():
 			return
 		case t := <-bc.transaction:
-			_ = bc.storage.AddTransaction(t)
+			if err := bc.storage.AddTransaction(t); err != nil {
+				log.Println(err.Error())
+			}
 		}
 	}
 }
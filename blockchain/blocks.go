@@ -21,8 +21,10 @@
 package blockchain
 
 import (
+	"encoding/hex"
 	"errors"
 	"log"
+	"umid/umid"
 
 	"github.com/umitop/libumi"
 )
@@ -43,30 +45,145 @@ func (bc *Blockchain) LastBlockHeight() (uint32, error) {
 	return bc.storage.LastBlockHeight()
 }
 
-// VerifyBlock ...
+// VerifyBlock returns a umid.ValidationError if the block itself is invalid, other errors mean that it could not be
+// checked against the local chain.
 func (bc *Blockchain) VerifyBlock(b []byte) error {
-	if _, ok := bc.approvedKeys[string((libumi.Block)(b).PublicKey())]; !ok {
-		log.Printf("block %X has invalid public key\n", (libumi.Block)(b).Hash())
+	blk := (libumi.Block)(b)
 
-		return errInvalidPubKey
+	if len(blk) < libumi.HeaderLength {
+		return umid.NewBlockError(libumi.ErrBlkInvalidLength)
 	}
 
-	return libumi.VerifyBlock(b)
-}
+	height, err := bc.blockHeight(blk)
+	if err != nil {
+		return err
+	}
 
-// ValidateBlock ...
-func (bc *Blockchain) ValidateBlock(b []byte) error {
-	blk := (libumi.Block)(b)
-	l := blk.TxCount()
+	if err := bc.VerifyPublicKeyAt(blk.PublicKey(), height); err != nil {
+		log.Printf("block %X has invalid public key\n", blk.Hash())
+
+		return umid.NewBlockError(err)
+	}
 
-	for i := uint16(0); i < l; i++ {
-		_ = blk.Transaction(i)
+	if err := libumi.VerifyBlock(b); err != nil {
+		return umid.NewBlockError(err)
 	}
 
 	return nil
 }
 
+// VerifyPublicKey checks that the key may sign the next block.
+func (bc *Blockchain) VerifyPublicKey(pub []byte) error {
+	height, err := bc.storage.LastBlockHeight()
+	if err != nil {
+		return err
+	}
+
+	return bc.VerifyPublicKeyAt(pub, height+1)
+}
+
+// VerifyPublicKeyAt checks that the key may sign the block at the height.
+func (bc *Blockchain) VerifyPublicKeyAt(pub []byte, height uint32) error {
+	for _, sg := range bc.approvedKeys[string(pub)] {
+		if sg.active(height) {
+			return nil
+		}
+	}
+
+	return errInvalidPubKey
+}
+
+// blockHeight returns the height the block will have once it is linked to its parent or umid.ErrUnknownParent
+// if the parent is not in the local chain.
+func (bc *Blockchain) blockHeight(blk libumi.Block) (uint32, error) {
+	if blk.Version() == libumi.Genesis {
+		return 1, nil
+	}
+
+	prv, err := bc.storage.BlockByHash(blk.PreviousBlockHash())
+	if err != nil {
+		if errors.Is(err, umid.ErrNotFound) {
+			err = umid.ErrUnknownParent
+		}
+
+		return 0, err
+	}
+
+	return prv.Height + 1, nil
+}
+
 // BlocksByHeight ...
 func (bc *Blockchain) BlocksByHeight(n uint64) ([][]byte, error) {
 	return bc.storage.BlocksByHeight(n)
 }
+
+// BlockByHeight ...
+func (bc *Blockchain) BlockByHeight(n uint32) (*umid.Block, error) {
+	blk, err := bc.storage.BlockByHeight(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return blockConvert(blk), nil
+}
+
+// BlockByHash ...
+func (bc *Blockchain) BlockByHash(s string) (*umid.Block, error) {
+	h, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	blk, err := bc.storage.BlockByHash(h)
+	if err != nil {
+		return nil, err
+	}
+
+	return blockConvert(blk), nil
+}
+
+// BlockTxHashes ...
+func (bc *Blockchain) BlockTxHashes(n uint32) ([]string, error) {
+	raw, err := bc.storage.BlockTxHashes(n)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, len(raw))
+
+	for i, h := range raw {
+		res[i] = hex.EncodeToString(h)
+	}
+
+	return res, nil
+}
+
+// BlockTransactions ...
+func (bc *Blockchain) BlockTransactions(n uint32, limit, offset int) ([]*umid.Transaction, error) {
+	raw, err := bc.storage.BlockTransactions(n, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*umid.Transaction, 0, len(raw))
+
+	for _, tx := range raw {
+		txs = append(txs, txConvert(tx))
+	}
+
+	return txs, nil
+}
+
+func blockConvert(b *umid.Block2) *umid.Block {
+	return &umid.Block{
+		Hash:           hex.EncodeToString(b.Hash),
+		Height:         b.Height,
+		Version:        b.Version,
+		PrevBlockHash:  hex.EncodeToString(b.PrevBlockHash),
+		MerkleRootHash: hex.EncodeToString(b.MerkleRootHash),
+		Timestamp:      b.CreatedAt.Unix(),
+		TxCount:        b.TxCount,
+		PublicKey:      hex.EncodeToString(b.PublicKey),
+		Confirmed:      b.Confirmed,
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain
+
+import (
+	"umid/umid"
+	"unicode/utf8"
+
+	"github.com/umitop/libumi"
+)
+
+const maxStructNameLength = 35
+
+// BuildTransaction returns unsigned transaction bytes, the nonce and signature are filled in by SignTransaction.
+func (bc *Blockchain) BuildTransaction(d umid.TxDraft) ([]byte, error) {
+	snd, err := libumi.NewAddressFromBech32(d.Sender)
+	if err != nil {
+		return nil, libumi.ErrTxInvalidSender
+	}
+
+	switch d.Version {
+	case libumi.Basic:
+		return buildTxBasic(snd, d)
+	case libumi.CreateStructure, libumi.UpdateStructure:
+		return buildTxStruct(snd, d)
+	case libumi.UpdateProfitAddress, libumi.UpdateFeeAddress, libumi.CreateTransitAddress,
+		libumi.DeleteTransitAddress:
+		return buildTxAddress(snd, d)
+	}
+
+	return nil, libumi.ErrTxInvalidVersion
+}
+
+// SignTransaction signs the transaction with the wallet key of its sender.
+func (bc *Blockchain) SignTransaction(b []byte) ([]byte, error) {
+	if bc.wallet == nil {
+		return nil, umid.ErrWalletDisabled
+	}
+
+	if len(b) != libumi.TxLength {
+		return nil, libumi.ErrTxInvalidLength
+	}
+
+	sec, ok := bc.wallet.SecretKey(libumi.TxBasic(b).Sender().PublicKey())
+	if !ok {
+		return nil, umid.ErrKeyNotFound
+	}
+
+	tx := make([]byte, libumi.TxLength)
+	copy(tx, b)
+
+	libumi.SignTx(tx, sec)
+
+	if err := libumi.VerifyTx(tx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+func buildTxBasic(snd libumi.Address, d umid.TxDraft) ([]byte, error) {
+	rcp, err := libumi.NewAddressFromBech32(d.Recipient)
+	if err != nil {
+		return nil, libumi.ErrTxInvalidRecipient
+	}
+
+	tx := libumi.NewTxBasic()
+	tx.SetSender(snd)
+	tx.SetRecipient(rcp)
+	tx.SetValue(d.Value)
+
+	return tx, nil
+}
+
+func buildTxStruct(snd libumi.Address, d umid.TxDraft) ([]byte, error) {
+	if !validPrefix(d.Prefix) {
+		return nil, libumi.ErrTxInvalidPrefix
+	}
+
+	if len(d.Name) > maxStructNameLength || !utf8.ValidString(d.Name) {
+		return nil, libumi.ErrTxInvalidName
+	}
+
+	tx := libumi.NewTxCrtStruct()
+	if d.Version == libumi.UpdateStructure {
+		tx = libumi.NewTxUpdStruct()
+	}
+
+	tx.SetSender(snd)
+	tx.SetPrefix(d.Prefix)
+	tx.SetName(d.Name)
+	tx.SetProfitPercent(d.ProfitPercent)
+	tx.SetFeePercent(d.FeePercent)
+
+	return tx, nil
+}
+
+func buildTxAddress(snd libumi.Address, d umid.TxDraft) ([]byte, error) {
+	adr, err := libumi.NewAddressFromBech32(d.Address)
+	if err != nil {
+		return nil, libumi.ErrTxInvalidRecipient
+	}
+
+	var tx libumi.TxAddress
+
+	switch d.Version {
+	case libumi.UpdateProfitAddress:
+		tx = libumi.NewTxUpdProfitAddr()
+	case libumi.UpdateFeeAddress:
+		tx = libumi.NewTxUpdFeeAddr()
+	case libumi.CreateTransitAddress:
+		tx = libumi.NewTxCrtTransitAddr()
+	default:
+		tx = libumi.NewTxDelTransitAddr()
+	}
+
+	tx.SetSender(snd)
+	tx.SetAddress(adr)
+
+	return tx, nil
+}
+
+func validPrefix(s string) bool {
+	if len(s) != 3 || s == "umi" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'a' || s[i] > 'z' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"umid/blockchain"
+	"umid/umid"
+
+	"github.com/umitop/libumi"
+)
+
+type walletMock map[string]ed25519.PrivateKey
+
+func (w walletMock) SecretKey(pub []byte) (ed25519.PrivateKey, bool) {
+	sec, ok := w[string(pub)]
+
+	return sec, ok
+}
+
+func TestBuildAndSignTransaction(t *testing.T) {
+	pub, sec, _ := ed25519.GenerateKey(nil)
+
+	snd := libumi.NewAddress()
+	snd.SetPublicKey(pub)
+
+	rcp := libumi.NewAddress()
+	rcp.SetPrefix("aaa")
+
+	bc := blockchain.NewBlockchain()
+
+	tests := []umid.TxDraft{
+		{Version: libumi.Basic, Sender: snd.Bech32(), Recipient: rcp.Bech32(), Value: 42},
+		{Version: libumi.CreateStructure, Sender: snd.Bech32(), Prefix: "abc", Name: "Test", ProfitPercent: 100},
+		{Version: libumi.UpdateStructure, Sender: snd.Bech32(), Prefix: "abc", Name: "Test", ProfitPercent: 500},
+		{Version: libumi.UpdateProfitAddress, Sender: snd.Bech32(), Address: rcp.Bech32()},
+		{Version: libumi.UpdateFeeAddress, Sender: snd.Bech32(), Address: rcp.Bech32()},
+		{Version: libumi.CreateTransitAddress, Sender: snd.Bech32(), Address: rcp.Bech32()},
+		{Version: libumi.DeleteTransitAddress, Sender: snd.Bech32(), Address: rcp.Bech32()},
+	}
+
+	if _, err := bc.SignTransaction(make([]byte, libumi.TxLength)); !errors.Is(err, umid.ErrWalletDisabled) {
+		t.Fatalf("expected disabled wallet, got %v", err)
+	}
+
+	bc.SetWallet(walletMock{string(pub): sec})
+
+	for _, d := range tests {
+		tx, err := bc.BuildTransaction(d)
+		if err != nil {
+			t.Fatalf("version %d: unexpected error: %v", d.Version, err)
+		}
+
+		if libumi.VersionTx(tx) != d.Version {
+			t.Errorf("wrong version: got %d want %d", libumi.VersionTx(tx), d.Version)
+		}
+
+		if libumi.VerifyTx(tx) == nil {
+			t.Errorf("version %d: unsigned transaction should not verify", d.Version)
+		}
+
+		signed, err := bc.SignTransaction(tx)
+		if err != nil {
+			t.Fatalf("version %d: unexpected error: %v", d.Version, err)
+		}
+
+		if err := libumi.VerifyTx(signed); err != nil {
+			t.Errorf("version %d: signed transaction is invalid: %v", d.Version, err)
+		}
+	}
+}
+
+func TestBuildTransactionInvalid(t *testing.T) {
+	snd := libumi.NewAddress()
+	bc := blockchain.NewBlockchain()
+
+	tests := []struct {
+		draft umid.TxDraft
+		err   error
+	}{
+		{umid.TxDraft{Version: libumi.Basic, Sender: "abc"}, libumi.ErrTxInvalidSender},
+		{umid.TxDraft{Version: libumi.Genesis, Sender: snd.Bech32()}, libumi.ErrTxInvalidVersion},
+		{umid.TxDraft{Version: libumi.Basic, Sender: snd.Bech32()}, libumi.ErrTxInvalidRecipient},
+		{umid.TxDraft{Version: libumi.CreateStructure, Sender: snd.Bech32(), Prefix: "umi"}, libumi.ErrTxInvalidPrefix},
+		{umid.TxDraft{Version: libumi.CreateStructure, Sender: snd.Bech32(), Prefix: "Ab1"}, libumi.ErrTxInvalidPrefix},
+		{
+			umid.TxDraft{Version: libumi.UpdateStructure, Sender: snd.Bech32(), Prefix: "abc", Name: string(make([]byte, 36))},
+			libumi.ErrTxInvalidName,
+		},
+		{umid.TxDraft{Version: libumi.UpdateFeeAddress, Sender: snd.Bech32()}, libumi.ErrTxInvalidRecipient},
+	}
+
+	for _, test := range tests {
+		if _, err := bc.BuildTransaction(test.draft); !errors.Is(err, test.err) {
+			t.Errorf("unexpected error: got %v want %v", err, test.err)
+		}
+	}
+}
+
+func TestSignTransactionUnknownKey(t *testing.T) {
+	bc := blockchain.NewBlockchain().SetWallet(walletMock{})
+
+	tx, _ := bc.BuildTransaction(umid.TxDraft{
+		Version: libumi.Basic, Sender: libumi.NewAddress().Bech32(), Recipient: libumi.NewAddress().Bech32(),
+	})
+
+	if _, err := bc.SignTransaction(tx); !errors.Is(err, umid.ErrKeyNotFound) {
+		t.Errorf("expected unknown key, got %v", err)
+	}
+}
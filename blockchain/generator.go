@@ -22,42 +22,214 @@ package blockchain
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
 	"log"
+	"os"
 	"sync"
 	"time"
+	"umid/umid"
+
+	"github.com/umitop/libumi"
+)
+
+const (
+	newBlockDelaySec = 10
+	maxBlockTxCount  = 10_000
 )
 
-const newBlockDelaySec = 10
+var errNoSecretKey = errors.New("no secret key")
 
 // Generator ...
 func (bc *Blockchain) Generator(ctx context.Context, wg *sync.WaitGroup) {
+	if os.Getenv("GENERATOR") != "on" {
+		return
+	}
+
+	if err := bc.verifySecretKey(); err != nil {
+		log.Println(err.Error())
+
+		return
+	}
+
 	wg.Add(1)
 	defer wg.Done()
 
 	for {
-		time.Sleep(newBlockDelaySec * time.Second)
-
 		select {
 		case <-ctx.Done():
 			return
-		default:
+		case <-time.After(newBlockDelaySec * time.Second):
 			generateNewBlock(bc)
 		}
 	}
 }
 
 func generateNewBlock(bc *Blockchain) {
-	m, err := bc.storage.Mempool()
+	// the key is fetched on every round so that a rotated key is picked up without a restart
+	sec := bc.keystore.SecretKey()
+
+	// transactions stay in the mempool until their block is confirmed, so the next block waits for that
+	if pending, err := hasPendingBlock(bc); err != nil || pending {
+		if err != nil {
+			log.Println(err.Error())
+		}
+
+		return
+	}
+
+	txs, err := collectTransactions(bc)
 	if err != nil {
 		log.Println(err.Error())
 
 		return
 	}
+
+	if len(txs) == 0 {
+		return
+	}
+
+	blk, txs, err := newValidBlock(bc, txs, sec)
+	if err != nil {
+		log.Println(err.Error())
+
+		return
+	}
+
+	if len(txs) == 0 {
+		return
+	}
+
+	// the confirmer removes the transactions from the mempool once the block is confirmed
+	if err := bc.AddBlock(blk); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+func hasPendingBlock(bc *Blockchain) (bool, error) {
+	height, err := bc.storage.LastBlockHeight()
+	if err != nil {
+		return false, err
+	}
+
+	blk, err := bc.storage.BlockByHeight(height)
+	if err != nil {
+		return false, err
+	}
+
+	return !blk.Confirmed, nil
+}
+
+func collectTransactions(bc *Blockchain) ([][]byte, error) {
+	m, err := bc.storage.Mempool()
+	if err != nil {
+		return nil, err
+	}
 	defer m.Close()
 
-	// now := time.Now().Unix()
+	txs := make([][]byte, 0)
 
 	for m.Next() {
-		return
+		if len(txs) == maxBlockTxCount {
+			break
+		}
+
+		t := make([]byte, len(m.Value()))
+		copy(t, m.Value())
+
+		if libumi.VersionTx(t) == libumi.Genesis || bc.VerifyTransaction(t) != nil {
+			continue
+		}
+
+		txs = append(txs, t)
+	}
+
+	return withoutConfirmed(bc, txs)
+}
+
+// withoutConfirmed drops transactions that are already confirmed but not yet purged from the mempool.
+func withoutConfirmed(bc *Blockchain, txs [][]byte) ([][]byte, error) {
+	hashes := make([][]byte, len(txs))
+
+	for i, t := range txs {
+		h := sha256.Sum256(t)
+		hashes[i] = h[:]
+	}
+
+	known, err := bc.storage.KnownTransactions(hashes)
+	if err != nil || len(known) == 0 {
+		return txs, err
+	}
+
+	skip := make(map[string]bool, len(known))
+	for _, h := range known {
+		skip[string(h)] = true
+	}
+
+	res := make([][]byte, 0, len(txs))
+
+	for i, t := range txs {
+		if !skip[string(hashes[i])] {
+			res = append(res, t)
+		}
 	}
+
+	return res, nil
+}
+
+// newValidBlock builds a block and drops the transactions rejected by ValidateBlock one by one.
+func newValidBlock(bc *Blockchain, txs [][]byte, sec ed25519.PrivateKey) (libumi.Block, [][]byte, error) {
+	for len(txs) > 0 {
+		blk, err := newBlock(bc, txs, sec)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		err = bc.ValidateBlock(blk)
+
+		var vErr *umid.ValidationError
+		if !errors.As(err, &vErr) || vErr.TxIndex < 0 {
+			return blk, txs, err
+		}
+
+		log.Println(err.Error())
+
+		txs = append(txs[:vErr.TxIndex], txs[vErr.TxIndex+1:]...)
+	}
+
+	return nil, txs, nil
+}
+
+func newBlock(bc *Blockchain, txs [][]byte, sec ed25519.PrivateKey) (libumi.Block, error) {
+	prv, err := bc.storage.LastBlockHash()
+	if err != nil {
+		return nil, err
+	}
+
+	blk := libumi.NewBlock()
+	blk.SetPreviousBlockHash(prv)
+	blk.SetTimestamp(uint32(time.Now().Unix()))
+
+	for _, t := range txs {
+		blk.AppendTransaction(t)
+	}
+
+	mrk, err := libumi.CalculateMerkleRoot(blk)
+	if err != nil {
+		return nil, err
+	}
+
+	blk.SetMerkleRootHash(mrk)
+	libumi.SignBlock(blk, sec)
+
+	return blk, nil
+}
+
+func (bc *Blockchain) verifySecretKey() error {
+	if bc.keystore == nil || bc.keystore.SecretKey() == nil {
+		return errNoSecretKey
+	}
+
+	return bc.VerifyPublicKey(bc.keystore.SecretKey().Public().(ed25519.PublicKey))
 }
@@ -0,0 +1,73 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain
+
+import (
+	"errors"
+	"math"
+	"time"
+	"umid/umid"
+
+	"github.com/umitop/libumi"
+)
+
+// interestPeriods is the number of compounding periods (seconds) in a month.
+const interestPeriods = 30 * 24 * 60 * 60
+
+var errProjectionInPast = errors.New("projection time is in the past")
+
+// AccrueInterest grows the value at the monthly effective rate given in hundredths of a percent over the given
+// number of seconds. It mirrors the compounding done by get_address_balance.
+func AccrueInterest(value uint64, percent uint16, seconds int64) uint64 {
+	if percent == 0 || seconds <= 0 {
+		return value
+	}
+
+	effective := float64(percent) / 10000
+	nominal := interestPeriods * (pow(1+effective, 1/float64(interestPeriods)) - 1)
+
+	return uint64(math.Floor(float64(value) * pow(1+nominal/interestPeriods, float64(seconds))))
+}
+
+// ProjectBalance forecasts the balance at the given time assuming the current percent does not change.
+func (bc *Blockchain) ProjectBalance(s string, until time.Time) (*umid.Projection, error) {
+	adr, err := libumi.NewAddressFromBech32(s)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if until.Before(now) {
+		return nil, errProjectionInPast
+	}
+
+	bal, err := bc.storage.Balance(adr, &now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &umid.Projection{
+		Value:     bal.Confirmed,
+		Projected: AccrueInterest(bal.Confirmed, bal.Interest, until.Unix()-now.Unix()),
+		Interest:  bal.Interest,
+		Until:     until.Unix(),
+	}, nil
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+	"umid/blockchain"
+	"umid/storage/postgres"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/umitop/libumi"
+)
+
+const month = 30 * 24 * 60 * 60
+
+// fixtures were computed with the double precision formula used by get_address_balance and the C library pow() that
+// Postgres calls. math.Pow gives 1_796_532_726, 141_000_000_041 and 33_643_442_156 for three of them, see pow.go.
+var interestFixtures = []struct {
+	value    uint64
+	percent  uint16
+	seconds  int64
+	expected uint64
+}{
+	{100, 0, 1000, 100},
+	{5_000_000, 1000, month, 5_500_000},
+	{5_000_000, 1000, 1, 5_000_000},
+	{10_000_000, 1500, 86400, 10_046_696},
+	{123_456_789, 2500, month * 12, 1_796_532_727},
+	{100_000_000_000, 4100, month, 141_000_000_036},
+	{1, 4100, month * 24, 3812},
+	{999_999_999, 3000, 3600, 1_000_364_460},
+	{50_000_000, 2000, month / 2, 54_772_255},
+	{7_777_777_777, 3600, 12_345_678, 33_643_442_163},
+}
+
+func TestAccrueInterest(t *testing.T) {
+	for _, f := range interestFixtures {
+		if got := blockchain.AccrueInterest(f.value, f.percent, f.seconds); got != f.expected {
+			t.Errorf("AccrueInterest(%d, %d, %d): got %d want %d", f.value, f.percent, f.seconds, got, f.expected)
+		}
+	}
+}
+
+// TestAccrueInterestMatchesPostgres runs get_address_balance on the fixtures. It migrates the database in
+// DATABASE_URL, which the GitLab test job provides, and rolls its own rows back.
+func TestAccrueInterestMatchesPostgres(t *testing.T) {
+	if os.Getenv("DATABASE_URL") == "" {
+		if os.Getenv("GITLAB_CI") != "" {
+			t.Fatal("DATABASE_URL is required in GitLab CI")
+		}
+
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	conn, err := pgxpool.Connect(ctx, os.Getenv("DATABASE_URL"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	postgres.Migrate(ctx, &sync.WaitGroup{}, conn)
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// a structure that can not exist so that structure_percent_log has no entries for it
+	adr := libumi.NewAddress()
+	adr.SetPrefix("zzz")
+
+	start := time.Unix(1_600_000_000, 0)
+
+	for i, f := range interestFixtures {
+		adr.SetPublicKey(append(make([]byte, 31), byte(i)))
+
+		_, err := tx.Exec(ctx, `insert into address_balance_confirmed (address, version, value, percent, type,
+created_tx_height, created_at, tx_height, updated_at) values ($1, $2, $3, $4, 'deposit', 0, $5, 0, $5)`,
+			[]byte(adr), int32(adr.Version()), int64(f.value), int16(f.percent), start)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got uint64
+
+		err = tx.QueryRow(ctx, `select confirmed_value from get_address_balance($1, $2)`,
+			[]byte(adr), start.Add(time.Duration(f.seconds)*time.Second)).Scan(&got)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if exp := blockchain.AccrueInterest(f.value, f.percent, f.seconds); got != exp {
+			t.Errorf("get_address_balance(%d, %d, %d): got %d, go math gives %d", f.value, f.percent, f.seconds, got, exp)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain
+
+import (
+	"encoding/hex"
+	"umid/umid"
+)
+
+// MempoolInfo ...
+func (bc *Blockchain) MempoolInfo() (*umid.MempoolInfo, error) {
+	raw, err := bc.storage.MempoolInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	inf := &umid.MempoolInfo{
+		Count:    raw.Count,
+		Bytes:    raw.Bytes,
+		Counters: bc.storage.MempoolCounters(),
+	}
+
+	if raw.OldestAt != nil {
+		inf.OldestAt = raw.OldestAt.Unix()
+	}
+
+	return inf, nil
+}
+
+// MempoolTransactions ...
+func (bc *Blockchain) MempoolTransactions(limit, offset int) ([]*umid.Transaction, error) {
+	raw, err := bc.storage.MempoolTransactions(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*umid.Transaction, 0, len(raw))
+
+	for _, tx := range raw {
+		txs = append(txs, txConvert(tx))
+	}
+
+	return txs, nil
+}
+
+// MempoolTransaction ...
+func (bc *Blockchain) MempoolTransaction(s string) (*umid.Transaction, error) {
+	hash, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := bc.storage.MempoolTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return txConvert(tx), nil
+}
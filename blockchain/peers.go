@@ -0,0 +1,77 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain
+
+import (
+	"time"
+	"umid/umid"
+)
+
+// Peers ...
+func (bc *Blockchain) Peers(limit int) ([]*umid.Peer, error) {
+	raw, err := bc.storage.Peers(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*umid.Peer, len(raw))
+
+	for i, p := range raw {
+		res[i] = &umid.Peer{
+			URL:      p.URL,
+			Failures: int(p.Failures),
+		}
+
+		if p.LastSeenAt != nil {
+			res[i].LastSeen = p.LastSeenAt.Unix()
+		}
+
+		if p.BannedUntil != nil {
+			res[i].BannedUntil = p.BannedUntil.Unix()
+		}
+	}
+
+	return res, nil
+}
+
+// SavePeers ...
+func (bc *Blockchain) SavePeers(peers []*umid.Peer) error {
+	raw := make([]*umid.Peer2, len(peers))
+
+	for i, p := range peers {
+		raw[i] = &umid.Peer2{
+			URL:      p.URL,
+			Failures: int32(p.Failures),
+		}
+
+		if p.LastSeen > 0 {
+			t := time.Unix(p.LastSeen, 0)
+			raw[i].LastSeenAt = &t
+		}
+
+		if p.BannedUntil > 0 {
+			t := time.Unix(p.BannedUntil, 0)
+			raw[i].BannedUntil = &t
+		}
+	}
+
+	return bc.storage.SavePeers(raw)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain
+
+import "math"
+
+// Postgres evaluates the interest formula with the C library pow(), which is correctly rounded, while math.Pow is
+// off by several ulps for the large exponents involved. For 100_000_000_000 at 41% over a month math.Pow yields
+// 141_000_000_041 where Postgres yields 141_000_000_036. pow below is computed in double-double precision and then
+// rounded, so the Go results agree with the database.
+
+// dd is a double-double number hi+lo with |lo| <= ulp(hi)/2.
+type dd struct {
+	hi, lo float64
+}
+
+var ddLn2 = dd{0.6931471805599453, 2.3190468138462996e-17}
+
+func twoSum(a, b float64) (float64, float64) {
+	s := a + b
+	bb := s - a
+
+	return s, (a - (s - bb)) + (b - bb)
+}
+
+func quickTwoSum(a, b float64) dd {
+	s := a + b
+
+	return dd{s, b - (s - a)}
+}
+
+func twoProd(a, b float64) (float64, float64) {
+	p := a * b
+
+	return p, math.FMA(a, b, -p)
+}
+
+func (x dd) add(y dd) dd {
+	s, e := twoSum(x.hi, y.hi)
+	t, f := twoSum(x.lo, y.lo)
+	r := quickTwoSum(s, e+t)
+
+	return quickTwoSum(r.hi, r.lo+f)
+}
+
+func (x dd) mul(y dd) dd {
+	p, e := twoProd(x.hi, y.hi)
+
+	return quickTwoSum(p, e+x.hi*y.lo+x.lo*y.hi)
+}
+
+func (x dd) mulFloat(b float64) dd {
+	p, e := twoProd(x.hi, b)
+
+	return quickTwoSum(p, e+x.lo*b)
+}
+
+func (x dd) divFloat(b float64) dd {
+	q1 := x.hi / b
+	p, e := twoProd(q1, b)
+	q2 := (((x.hi - p) - e) + x.lo) / b
+
+	return quickTwoSum(q1, q2)
+}
+
+func (x dd) ldexp(k int) dd {
+	return dd{math.Ldexp(x.hi, k), math.Ldexp(x.lo, k)}
+}
+
+func ddExp(x dd) dd {
+	const (
+		squarings = 10
+		terms     = 10
+	)
+
+	k := math.Round(x.hi / math.Ln2)
+	r := x.add(ddLn2.mulFloat(-k)).ldexp(-squarings)
+
+	sum, term := dd{1, 0}.add(r), r
+	for n := 2; n <= terms; n++ {
+		term = term.mul(r).divFloat(float64(n))
+		sum = sum.add(term)
+	}
+
+	for i := 0; i < squarings; i++ {
+		sum = sum.mul(sum)
+	}
+
+	return sum.ldexp(int(k))
+}
+
+// ddLog refines math.Log with a Newton step: y1 = y0 + (x - exp(y0)) / exp(y0).
+func ddLog(x float64) dd {
+	y0 := math.Log(x)
+	e := ddExp(dd{y0, 0})
+	d := dd{x, 0}.add(dd{-e.hi, -e.lo})
+
+	return quickTwoSum(y0, d.hi/e.hi)
+}
+
+// pow returns x**y rounded to the nearest float64.
+func pow(x, y float64) float64 {
+	return ddExp(ddLog(x).mulFloat(y)).hi
+}
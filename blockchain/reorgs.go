@@ -0,0 +1,123 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"umid/umid"
+
+	"github.com/umitop/libumi"
+)
+
+// maxReorgDepth limits how many local blocks a peer can make us roll back.
+const maxReorgDepth = 1000
+
+var (
+	errShortBranch  = errors.New("branch is not longer than the local chain")
+	errDeepReorg    = errors.New("fork is too deep")
+	errBrokenBranch = errors.New("block does not follow the previous one")
+)
+
+// Reorganize replaces the local blocks above the fork height with a longer branch. Signatures and links of the
+// whole branch are verified first, then storage validates and confirms it block by block and only commits the
+// rollback when every block is valid.
+func (bc *Blockchain) Reorganize(fork uint32, blocks [][]byte, peer string) error {
+	last, err := bc.storage.LastBlockHeight()
+	if err != nil {
+		return err
+	}
+
+	if fork >= last || uint32(len(blocks)) <= last-fork {
+		return errShortBranch
+	}
+
+	if last-fork > maxReorgDepth {
+		return errDeepReorg
+	}
+
+	base, err := bc.storage.BlockByHeight(fork)
+	if err != nil {
+		return err
+	}
+
+	if err := bc.verifyBranch(base.Hash, fork, blocks); err != nil {
+		return err
+	}
+
+	return bc.storage.Reorganize(fork, blocks, peer, validateBlock)
+}
+
+// verifyBranch checks that the blocks are valid and form a chain starting right after the given one.
+func (bc *Blockchain) verifyBranch(prev []byte, height uint32, blocks [][]byte) error {
+	for _, b := range blocks {
+		blk := (libumi.Block)(b)
+
+		if len(blk) < libumi.HeaderLength {
+			return umid.NewBlockError(libumi.ErrBlkInvalidLength)
+		}
+
+		if !bytes.Equal(blk.PreviousBlockHash(), prev) {
+			return umid.NewBlockError(errBrokenBranch)
+		}
+
+		height++
+
+		if err := bc.VerifyPublicKeyAt(blk.PublicKey(), height); err != nil {
+			return umid.NewBlockError(err)
+		}
+
+		if err := libumi.VerifyBlock(b); err != nil {
+			return umid.NewBlockError(err)
+		}
+
+		prev = blk.Hash()
+	}
+
+	return nil
+}
+
+// Reorgs ...
+func (bc *Blockchain) Reorgs(limit int) ([]*umid.Reorg, error) {
+	raw, err := bc.storage.Reorgs(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*umid.Reorg, len(raw))
+
+	for i, r := range raw {
+		res[i] = &umid.Reorg{
+			Height:    r.Height,
+			Hash:      hex.EncodeToString(r.Hash),
+			OldHeight: r.OldHeight,
+			OldHash:   hex.EncodeToString(r.OldHash),
+			Timestamp: r.CreatedAt.Unix(),
+		}
+
+		if r.Peer != nil {
+			res[i].Peer = *r.Peer
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"umid/umid"
+
+	"github.com/umitop/libumi"
+)
+
+type reorgStorage struct {
+	umid.IStorage
+	last     uint32
+	base     []byte
+	branch   [][]byte
+	validate umid.BranchValidator
+}
+
+func (s *reorgStorage) LastBlockHeight() (uint32, error) {
+	return s.last, nil
+}
+
+func (s *reorgStorage) BlockByHeight(uint32) (*umid.Block2, error) {
+	return &umid.Block2{Hash: s.base}, nil
+}
+
+func (s *reorgStorage) BlockByHash([]byte) (*umid.Block2, error) {
+	return &umid.Block2{}, nil
+}
+
+// KnownTransactions reports every transaction as confirmed, so the validator rejects any block it is given.
+func (s *reorgStorage) KnownTransactions(hashes [][]byte) ([][]byte, error) {
+	return hashes, nil
+}
+
+func (s *reorgStorage) Reorganize(_ uint32, blocks [][]byte, _ string, fn umid.BranchValidator) error {
+	s.branch, s.validate = blocks, fn
+
+	return nil
+}
+
+func signedBlock(prev []byte, sec ed25519.PrivateKey) []byte {
+	snd, rcp := libumi.NewAddress(), libumi.NewAddress()
+	snd.SetPublicKey(sec.Public().(ed25519.PublicKey))
+	rcp.SetPublicKey(make([]byte, 32))
+
+	tx := libumi.NewTxBasic()
+	tx.SetSender(snd)
+	tx.SetRecipient(rcp)
+	tx.SetValue(1)
+	libumi.SignTx(tx, sec)
+
+	blk := libumi.NewBlock()
+	blk.SetPreviousBlockHash(prev)
+	blk.AppendTransaction(tx)
+
+	mrk, _ := libumi.CalculateMerkleRoot(blk)
+	blk.SetMerkleRootHash(mrk)
+	libumi.SignBlock(blk, sec)
+
+	return blk
+}
+
+func signedBranch(prev []byte, n int, sec ed25519.PrivateKey) [][]byte {
+	res := make([][]byte, n)
+
+	for i := range res {
+		res[i] = signedBlock(prev, sec)
+		prev = (libumi.Block)(res[i]).Hash()
+	}
+
+	return res
+}
+
+func TestReorganize(t *testing.T) {
+	sec := ed25519.NewKeyFromSeed(make([]byte, 32))
+	stranger := ed25519.NewKeyFromSeed(append(make([]byte, 31), 1))
+
+	lst, err := parseSigners(hex.EncodeToString(sec.Public().(ed25519.PublicKey)) + "@1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := indexSigners(lst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := make([]byte, 32)
+	base[0] = 1
+
+	broken := signedBranch(base, 3, sec)
+	broken[2] = signedBlock(base, sec)
+
+	tests := []struct {
+		name   string
+		blocks [][]byte
+		err    bool
+	}{
+		{"longer branch", signedBranch(base, 3, sec), false},
+		{"branch of the same length", signedBranch(base, 2, sec), true},
+		{"broken link", broken, true},
+		{"unknown signer", signedBranch(base, 3, stranger), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &reorgStorage{last: 12, base: base}
+			bc := &Blockchain{approvedKeys: keys, storage: s}
+
+			err := bc.Reorganize(10, tt.blocks, "http://peer")
+
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+
+				if s.branch != nil {
+					t.Error("storage must not be touched when the branch is rejected")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(s.branch) != len(tt.blocks) || s.validate == nil {
+				t.Fatal("branch should be handed to storage together with the validator")
+			}
+
+			if err := s.validate(s, tt.blocks[0]); !errors.Is(err, umid.ErrDuplicateTx) {
+				t.Errorf("validator should read through the given storage, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	errInvalidSigner = errors.New("invalid signer")
+	errNoSigners     = errors.New("no signers configured for the network")
+)
+
+// signer is a public key approved to sign blocks in the height range [From, To).
+// A zero To means the key has not been revoked.
+type signer struct {
+	PublicKey string `json:"public_key"`
+	From      uint32 `json:"from_height"`
+	To        uint32 `json:"to_height,omitempty"`
+}
+
+func (s signer) active(height uint32) bool {
+	return height >= s.From && (s.To == 0 || height < s.To)
+}
+
+func defaultSigners() map[string][]signer {
+	return map[string][]signer{
+		"mainnet": {
+			{PublicKey: "45885c9687d799a4d1f4d786d8639274d293ed024ad7f7a436715d6217c9f72b"},
+		},
+		"testnet": {
+			{PublicKey: "45885c9687d799a4d1f4d786d8639274d293ed024ad7f7a436715d6217c9f72b"},
+		},
+	}
+}
+
+func network() string {
+	if n := os.Getenv("NETWORK"); n != "" {
+		return n
+	}
+
+	return "mainnet"
+}
+
+// loadSigners returns the signer schedule for the current NETWORK.
+// SIGNERS_FILE points to a JSON object keyed by network name, e.g.
+//
+//	{"testnet": [{"public_key": "<hex>", "from_height": 1, "to_height": 1000}]}
+//
+// SIGNERS overrides it with a comma separated list of <hex>[@from[-to]], e.g.
+//
+//	SIGNERS=<hex1>@1-1000,<hex2>@1000
+func loadSigners() (map[string][]signer, error) {
+	sets := defaultSigners()
+
+	if path, ok := os.LookupEnv("SIGNERS_FILE"); ok {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(b, &sets); err != nil {
+			return nil, err
+		}
+	}
+
+	lst := sets[network()]
+
+	if val, ok := os.LookupEnv("SIGNERS"); ok {
+		var err error
+		if lst, err = parseSigners(val); err != nil {
+			return nil, err
+		}
+	}
+
+	return indexSigners(lst)
+}
+
+func parseSigners(s string) ([]signer, error) {
+	lst := make([]signer, 0)
+
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		sg := signer{}
+		parts := strings.SplitN(item, "@", 2)
+		sg.PublicKey = parts[0]
+
+		if len(parts) == 2 {
+			rng := strings.SplitN(parts[1], "-", 2)
+
+			from, err := strconv.ParseUint(rng[0], 10, 32)
+			if err != nil {
+				return nil, errInvalidSigner
+			}
+
+			sg.From = uint32(from)
+
+			if len(rng) == 2 {
+				to, err := strconv.ParseUint(rng[1], 10, 32)
+				if err != nil {
+					return nil, errInvalidSigner
+				}
+
+				sg.To = uint32(to)
+			}
+		}
+
+		lst = append(lst, sg)
+	}
+
+	return lst, nil
+}
+
+func indexSigners(lst []signer) (map[string][]signer, error) {
+	if len(lst) == 0 {
+		return nil, errNoSigners
+	}
+
+	idx := make(map[string][]signer, len(lst))
+
+	for _, sg := range lst {
+		b, err := hex.DecodeString(sg.PublicKey)
+		if err != nil || len(b) != ed25519.PublicKeySize || (sg.To != 0 && sg.To <= sg.From) {
+			return nil, errInvalidSigner
+		}
+
+		idx[string(b)] = append(idx[string(b)], sg)
+	}
+
+	return idx, nil
+}
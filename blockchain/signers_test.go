@@ -0,0 +1,142 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseSigners(t *testing.T) {
+	tests := []struct {
+		name string
+		val  string
+		want []signer
+		err  error
+	}{
+		{"bare key", "aa", []signer{{PublicKey: "aa"}}, nil},
+		{"open range", "aa@10", []signer{{PublicKey: "aa", From: 10}}, nil},
+		{"closed range", "aa@10-20", []signer{{PublicKey: "aa", From: 10, To: 20}}, nil},
+		{"list", " aa@1-5 , bb@5,", []signer{{PublicKey: "aa", From: 1, To: 5}, {PublicKey: "bb", From: 5}}, nil},
+		{"empty", "", []signer{}, nil},
+		{"bad from", "aa@x", nil, errInvalidSigner},
+		{"bad to", "aa@1-x", nil, errInvalidSigner},
+		{"negative", "aa@-1", nil, errInvalidSigner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSigners(tt.val)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expected %v, got %v", tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIndexSignersInvalid(t *testing.T) {
+	key := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name string
+		lst  []signer
+		err  error
+	}{
+		{"empty set", nil, errNoSigners},
+		{"not hex", []signer{{PublicKey: "zz"}}, errInvalidSigner},
+		{"short key", []signer{{PublicKey: "abab"}}, errInvalidSigner},
+		{"inverted range", []signer{{PublicKey: key, From: 10, To: 5}}, errInvalidSigner},
+		{"empty range", []signer{{PublicKey: key, From: 10, To: 10}}, errInvalidSigner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := indexSigners(tt.lst); !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestVerifyPublicKeyAt(t *testing.T) {
+	retired := strings.Repeat("11", 32)
+	current := strings.Repeat("22", 32)
+	revoked := strings.Repeat("33", 32)
+
+	// retired signed [1, 100), current took over at 100,
+	// revoked overlapped both between 50 and 150 and was later revoked
+	lst, err := parseSigners(retired + "@1-100," + current + "@100," + revoked + "@50-150")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := indexSigners(lst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bc := &Blockchain{approvedKeys: keys}
+
+	tests := []struct {
+		name   string
+		key    string
+		height uint32
+		valid  bool
+	}{
+		{"retired key on historical block", retired, 99, true},
+		{"retired key on first block", retired, 1, true},
+		{"retired key on new block", retired, 100, false},
+		{"current key before its range", current, 99, false},
+		{"current key on new block", current, 1_000_000, true},
+		{"overlapping key in both ranges", revoked, 75, true},
+		{"overlapping key with current", revoked, 120, true},
+		{"revoked key on new block", revoked, 150, false},
+		{"revoked key before its range", revoked, 49, false},
+		{"unknown key", strings.Repeat("44", 32), 10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub, _ := hex.DecodeString(tt.key)
+
+			err := bc.VerifyPublicKeyAt(pub, tt.height)
+			if tt.valid && err != nil {
+				t.Errorf("expected key to be active at %d, got %v", tt.height, err)
+			}
+
+			if !tt.valid && err == nil {
+				t.Errorf("expected key to be rejected at %d", tt.height)
+			}
+		})
+	}
+}
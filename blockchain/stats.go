@@ -0,0 +1,51 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain
+
+import (
+	"umid/umid"
+)
+
+// NetworkStats ...
+func (bc *Blockchain) NetworkStats(days int) (*umid.NetworkStats, error) {
+	s, err := bc.storage.NetworkStats(days)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &umid.NetworkStats{
+		Height:          s.Height,
+		TotalSupply:     s.TotalSupply,
+		StructureValue:  s.StructureValue,
+		StructureCount:  s.StructureCount,
+		FundedAddresses: s.FundedAddresses,
+		TxCount:         s.TxCount,
+		WindowDays:      days,
+		TxPerDay:        float64(s.WindowTxCount) / float64(days),
+		UpdatedAt:       s.UpdatedAt.Unix(),
+	}
+
+	if s.WindowBlocks > 1 && s.WindowFirst != nil && s.WindowLast != nil {
+		res.AvgBlockInterval = s.WindowLast.Sub(*s.WindowFirst).Seconds() / float64(s.WindowBlocks-1)
+	}
+
+	return res, nil
+}
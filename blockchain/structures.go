@@ -76,3 +76,92 @@ func structConvert(s *umid.Structure2) *umid.Structure {
 
 	return res
 }
+
+// StructureHistory ...
+func (bc *Blockchain) StructureHistory(p string, before *int32, limit int) (*umid.StructureHistory, error) {
+	h, err := bc.storage.StructureHistory(p, before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &umid.StructureHistory{
+		Levels:   make([]*umid.StructureLevel, len(h.Levels)),
+		Settings: make([]*umid.StructureSettings, len(h.Settings)),
+		Balances: make([]*umid.StructureBalance, len(h.Balances)),
+	}
+
+	for i, l := range h.Levels {
+		res.Levels[i] = &umid.StructureLevel{
+			Level:          l.Level,
+			Percent:        l.Percent,
+			DevPercent:     l.DevPercent,
+			ProfitPercent:  l.ProfitPercent,
+			DepositPercent: l.DepositPercent,
+			BlockHeight:    l.BlockHeight,
+			UpdatedAt:      l.UpdatedAt.Unix(),
+			Comment:        comment(l.Comment),
+		}
+	}
+
+	for i, c := range h.Settings {
+		res.Settings[i] = &umid.StructureSettings{
+			Name:          c.Name,
+			ProfitPercent: c.ProfitPercent,
+			FeePercent:    c.FeePercent,
+			DevAddress:    convertAddress(c.DevAddress),
+			MasterAddress: convertAddress(c.MasterAddress),
+			ProfitAddress: convertAddress(c.ProfitAddress),
+			FeeAddress:    convertAddress(c.FeeAddress),
+			TxHeight:      c.TxHeight,
+			CreatedAt:     c.CreatedAt.Unix(),
+			Comment:       comment(c.Comment),
+		}
+	}
+
+	for i, b := range h.Balances {
+		res.Balances[i] = &umid.StructureBalance{
+			Value:     b.Value,
+			Percent:   b.Percent,
+			TxHeight:  b.TxHeight,
+			UpdatedAt: b.UpdatedAt.Unix(),
+			Comment:   comment(b.Comment),
+		}
+	}
+
+	return res, nil
+}
+
+// StructureAddresses ...
+func (bc *Blockchain) StructureAddresses(p string) ([]*umid.StructureAddress, error) {
+	raw, err := bc.storage.StructureAddresses(p)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*umid.StructureAddress, len(raw))
+
+	for i, a := range raw {
+		res[i] = &umid.StructureAddress{
+			Address:         convertAddress(a.Address),
+			Type:            a.Type,
+			CreatedTxHeight: a.CreatedTxHeight,
+			DeletedTxHeight: a.DeletedTxHeight,
+			CreatedAt:       a.CreatedAt.Unix(),
+		}
+
+		if a.DeletedAt != nil {
+			t := a.DeletedAt.Unix()
+			res[i].DeletedAt = &t
+		}
+	}
+
+	return res, nil
+}
+
+func comment(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
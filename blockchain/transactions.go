@@ -21,6 +21,7 @@
 package blockchain
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"umid/umid"
@@ -36,6 +37,10 @@ func (bc *Blockchain) AddTransaction(b []byte) error {
 		return err
 	}
 
+	if err := bc.admitTransaction(b); err != nil {
+		return err
+	}
+
 	select {
 	case bc.transaction <- b:
 		break
@@ -47,13 +52,13 @@ func (bc *Blockchain) AddTransaction(b []byte) error {
 }
 
 // TransactionsByAddress ...
-func (bc *Blockchain) TransactionsByAddress(s string) ([]*umid.Transaction, error) {
+func (bc *Blockchain) TransactionsByAddress(s string, f umid.TxFilter) ([]*umid.Transaction, error) {
 	adr, err := libumi.NewAddressFromBech32(s)
 	if err != nil {
 		return nil, err
 	}
 
-	raw, err := bc.storage.TransactionsByAddress(adr)
+	raw, err := bc.storage.TransactionsByAddress(adr, f)
 	if err != nil {
 		return nil, err
 	}
@@ -61,27 +66,42 @@ func (bc *Blockchain) TransactionsByAddress(s string) ([]*umid.Transaction, erro
 	txs := make([]*umid.Transaction, 0, len(raw))
 
 	for _, tx := range raw {
-		t := &umid.Transaction{
-			Hash:        hex.EncodeToString(tx.Hash),
-			Height:      tx.Height,
-			ConfirmedAt: tx.ConfirmedAt.Unix(),
-			BlockHeight: tx.BlockHeight,
-			BlockTxIdx:  tx.BlockTxIdx,
-			Version:     tx.Version,
-			Sender:      convertAddress(tx.Sender),
-			Recipient:   convertAddress(tx.Recipient),
-			Value:       tx.Value,
-			FeeAddress:  convertAddress(tx.FeeAddress),
-			FeeValue:    tx.FeeValue,
-			Structure:   tx.Structure,
-		}
-
-		txs = append(txs, t)
+		txs = append(txs, txConvert(tx))
 	}
 
 	return txs, nil
 }
 
+// Transaction returns a confirmed transaction or, failing that, a pending one from the mempool.
+func (bc *Blockchain) Transaction(s string) (*umid.Transaction, error) {
+	hash, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := bc.storage.TransactionByHash(hash)
+	if err == nil {
+		t := txConvert(tx)
+		t.Status = umid.TxStatusConfirmed
+		t.Confirmations = tx.Confirmations
+
+		return t, nil
+	}
+
+	if !errors.Is(err, umid.ErrNotFound) {
+		return nil, err
+	}
+
+	if tx, err = bc.storage.MempoolTransaction(hash); err != nil {
+		return nil, err
+	}
+
+	t := txConvert(tx)
+	t.Status = umid.TxStatusPending
+
+	return t, nil
+}
+
 // VerifyTransaction ...
 func (bc *Blockchain) VerifyTransaction(t []byte) error {
 	const (
@@ -99,6 +119,88 @@ func (bc *Blockchain) VerifyTransaction(t []byte) error {
 	return libumi.VerifyTx(t)
 }
 
+// admitTransaction checks the transaction against the confirmed state and the mempool.
+func (bc *Blockchain) admitTransaction(t []byte) error {
+	h := sha256.Sum256(t)
+
+	known, err := bc.storage.KnownTransactions([][]byte{h[:]})
+	if err != nil {
+		return err
+	}
+
+	if len(known) > 0 {
+		return umid.ErrTxConfirmed
+	}
+
+	sender := (libumi.TxBasic)(t).Sender()
+
+	seen, err := bc.storage.HasNonce(sender, t[77:85])
+	if err != nil {
+		return err
+	}
+
+	if seen {
+		return umid.ErrNonceReplay
+	}
+
+	return bc.checkFunds(t, sender)
+}
+
+func (bc *Blockchain) checkFunds(t []byte, sender []byte) error {
+	var cost uint64
+
+	switch libumi.VersionTx(t) {
+	case libumi.Basic:
+		cost = (libumi.TxBasic)(t).Value()
+	case libumi.CreateStructure:
+		cost = createStructureCost
+	default:
+		return nil
+	}
+
+	bal, err := bc.storage.Balance(sender, nil)
+	if err != nil {
+		return err
+	}
+
+	pending, err := bc.storage.PendingSpends(sender, createStructureCost)
+	if err != nil {
+		return err
+	}
+
+	if bal.Confirmed < pending+cost {
+		return umid.ErrInsufficientFunds
+	}
+
+	return nil
+}
+
+func txConvert(tx *umid.Transaction2) *umid.Transaction {
+	t := &umid.Transaction{
+		Hash:        hex.EncodeToString(tx.Hash),
+		Height:      tx.Height,
+		BlockHeight: tx.BlockHeight,
+		BlockTxIdx:  tx.BlockTxIdx,
+		Version:     tx.Version,
+		Sender:      convertAddress(tx.Sender),
+		Recipient:   convertAddress(tx.Recipient),
+		Value:       tx.Value,
+		FeeAddress:  convertAddress(tx.FeeAddress),
+		FeeValue:    tx.FeeValue,
+		Structure:   tx.Structure,
+	}
+
+	if !tx.ConfirmedAt.IsZero() {
+		t.ConfirmedAt = tx.ConfirmedAt.Unix()
+	}
+
+	if !tx.CreatedAt.IsZero() {
+		t.CreatedAt = tx.CreatedAt.Unix()
+	}
+
+	return t
+}
+
 func convertAddress(b []byte) (s string) {
 	if b != nil {
 		s = (libumi.Address)(b).Bech32()
@@ -0,0 +1,316 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"time"
+	"umid/umid"
+
+	"github.com/umitop/libumi"
+)
+
+const createStructureCost = 5_000_000
+
+// blockState tracks the effect of the transactions that have already been checked in the block being validated.
+// Balances are upper bounds: every credit confirm_tx__basic may make is counted in full and the debits it makes on
+// behalf of structures are ignored, so a transaction is only rejected when plpgsql would reject it as well.
+type blockState struct {
+	storage    umid.IStorage
+	time       time.Time
+	balances   map[string]int64
+	types      map[string]string
+	devCredits map[string]int64
+	unbounded  map[string]bool
+	structures map[string]*umid.Structure2
+}
+
+// ValidateBlock checks the block against the current confirmed state.
+func (bc *Blockchain) ValidateBlock(b []byte) error {
+	return validateBlock(bc.storage, b)
+}
+
+// validateBlock checks the block against the confirmed state seen through the storage, which is a transaction
+// when a branch is validated during a reorganization.
+func validateBlock(s umid.IStorage, b []byte) error {
+	blk := (libumi.Block)(b)
+
+	if err := validateTimestamp(s, blk); err != nil {
+		return err
+	}
+
+	if err := validateUniqueness(s, blk); err != nil {
+		return err
+	}
+
+	st := &blockState{
+		storage:    s,
+		time:       time.Unix(int64(blk.Timestamp()), 0),
+		balances:   make(map[string]int64),
+		types:      make(map[string]string),
+		devCredits: make(map[string]int64),
+		unbounded:  make(map[string]bool),
+		structures: make(map[string]*umid.Structure2),
+	}
+
+	for i, l := uint16(0), blk.TxCount(); i < l; i++ {
+		if err := st.apply(blk.Transaction(i)); err != nil {
+			var vErr *umid.ValidationError
+			if errors.As(err, &vErr) {
+				vErr.TxIndex = int(i)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateTimestamp(s umid.IStorage, blk libumi.Block) error {
+	if blk.Version() == libumi.Genesis {
+		return nil
+	}
+
+	prv, err := s.BlockByHash(blk.PreviousBlockHash())
+	if err != nil {
+		return err
+	}
+
+	if int64(blk.Timestamp()) < prv.CreatedAt.Unix() {
+		return umid.NewBlockError(umid.ErrInvalidTimestamp)
+	}
+
+	return nil
+}
+
+func validateUniqueness(s umid.IStorage, blk libumi.Block) error {
+	l := blk.TxCount()
+	hashes := make([][]byte, l)
+	seen := make(map[[32]byte]struct{}, l)
+
+	for i := uint16(0); i < l; i++ {
+		h := sha256.Sum256(blk.Transaction(i))
+		if _, ok := seen[h]; ok {
+			return umid.NewTxError(int(i), umid.ErrDuplicateTx)
+		}
+
+		seen[h] = struct{}{}
+		hashes[i] = h[:]
+	}
+
+	known, err := s.KnownTransactions(hashes)
+	if err != nil {
+		return err
+	}
+
+	if len(known) == 0 {
+		return nil
+	}
+
+	for i, h := range hashes {
+		for _, k := range known {
+			if bytes.Equal(h, k) {
+				return umid.NewTxError(i, umid.ErrDuplicateTx)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (st *blockState) apply(t []byte) error {
+	switch libumi.VersionTx(t) {
+	case libumi.Basic:
+		return st.applyBasic((libumi.TxBasic)(t))
+	case libumi.CreateStructure:
+		return st.applyCreateStructure((libumi.TxStruct)(t))
+	case libumi.UpdateStructure:
+		tx := (libumi.TxStruct)(t)
+
+		return st.checkMaster(tx.Prefix(), tx.Sender())
+	case libumi.UpdateProfitAddress, libumi.UpdateFeeAddress, libumi.CreateTransitAddress, libumi.DeleteTransitAddress:
+		tx := (libumi.TxAddress)(t)
+		pfx := tx.Address().Prefix()
+
+		// these move balances between the structure addresses, which is not modelled
+		st.unbounded[pfx] = true
+
+		return st.checkMaster(pfx, tx.Sender())
+	}
+
+	return nil
+}
+
+func (st *blockState) applyBasic(tx libumi.TxBasic) error {
+	var s *umid.Structure2
+
+	if pfx := tx.Recipient().Prefix(); pfx != "umi" {
+		var err error
+		if s, err = st.structure(pfx); err != nil {
+			return err
+		}
+	}
+
+	if err := st.spend(tx.Sender(), tx.Value()); err != nil {
+		return err
+	}
+
+	if err := st.credit(tx.Recipient(), tx.Value()); err != nil {
+		return err
+	}
+
+	if s == nil {
+		return nil
+	}
+
+	// the fee, profit and dev addresses may be credited up to the full value
+	if err := st.credit(s.FeeAddress, tx.Value()); err != nil {
+		return err
+	}
+
+	if err := st.credit(s.ProfitAddress, tx.Value()); err != nil {
+		return err
+	}
+
+	st.devCredits[s.Prefix] += int64(tx.Value())
+
+	return nil
+}
+
+func (st *blockState) applyCreateStructure(tx libumi.TxStruct) error {
+	_, err := st.structure(tx.Prefix())
+
+	switch {
+	case err == nil:
+		return invalidTx(umid.ErrStructureExists)
+	case !errors.Is(err, umid.ErrStructureNotFound):
+		return err
+	}
+
+	if err := st.spend(tx.Sender(), createStructureCost); err != nil {
+		return err
+	}
+
+	st.structures[tx.Prefix()] = &umid.Structure2{
+		Prefix:        tx.Prefix(),
+		MasterAddress: tx.Sender(),
+	}
+
+	// the dev and profit addresses of the new structure are not known to storage yet
+	st.unbounded[tx.Prefix()] = true
+
+	return nil
+}
+
+func (st *blockState) checkMaster(pfx string, sender []byte) error {
+	s, err := st.structure(pfx)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(s.MasterAddress, sender) {
+		return invalidTx(umid.ErrNotMasterAddress)
+	}
+
+	return nil
+}
+
+func (st *blockState) spend(adr []byte, val uint64) error {
+	if err := st.load(adr); err != nil {
+		return err
+	}
+
+	key := string(adr)
+	pfx := libumi.Address(adr).Prefix()
+	available := st.balances[key]
+
+	if st.types[key] == "dev" {
+		available += st.devCredits[pfx]
+	}
+
+	if !st.unbounded[pfx] && available < int64(val) {
+		return invalidTx(umid.ErrInsufficientFunds)
+	}
+
+	st.balances[key] -= int64(val)
+
+	return nil
+}
+
+func (st *blockState) credit(adr []byte, val uint64) error {
+	if len(adr) == 0 {
+		return nil
+	}
+
+	if err := st.load(adr); err != nil {
+		return err
+	}
+
+	st.balances[string(adr)] += int64(val)
+
+	return nil
+}
+
+// load fetches the confirmed balance at the block time the first time the address is seen in the block.
+func (st *blockState) load(adr []byte) error {
+	key := string(adr)
+
+	if _, ok := st.balances[key]; ok {
+		return nil
+	}
+
+	bal, err := st.storage.Balance(adr, &st.time)
+	if err != nil {
+		return err
+	}
+
+	st.balances[key] = int64(bal.Confirmed)
+	st.types[key] = bal.Type
+
+	return nil
+}
+
+func (st *blockState) structure(pfx string) (*umid.Structure2, error) {
+	if s, ok := st.structures[pfx]; ok {
+		return s, nil
+	}
+
+	s, err := st.storage.StructureByPrefix(pfx)
+	if err != nil {
+		if errors.Is(err, umid.ErrNotFound) {
+			return nil, invalidTx(umid.ErrStructureNotFound)
+		}
+
+		return nil, err
+	}
+
+	st.structures[pfx] = s
+
+	return s, nil
+}
+
+// invalidTx returns a validation error; its index is filled in by ValidateBlock.
+func invalidTx(err error) error {
+	return &umid.ValidationError{Err: err}
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package blockchain_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"testing"
+	"time"
+	"umid/blockchain"
+	"umid/umid"
+
+	"github.com/umitop/libumi"
+)
+
+type validatorStorage struct {
+	umid.IStorage
+	parent     time.Time
+	balances   map[string]*umid.Balance
+	structures map[string]*umid.Structure2
+	known      [][]byte
+}
+
+func (s *validatorStorage) Balance(adr []byte, _ *time.Time) (*umid.Balance, error) {
+	if b, ok := s.balances[string(adr)]; ok {
+		return b, nil
+	}
+
+	return &umid.Balance{Type: "umi"}, nil
+}
+
+func (s *validatorStorage) StructureByPrefix(pfx string) (*umid.Structure2, error) {
+	if st, ok := s.structures[pfx]; ok {
+		return st, nil
+	}
+
+	return nil, umid.ErrNotFound
+}
+
+func (s *validatorStorage) KnownTransactions(hashes [][]byte) ([][]byte, error) {
+	res := make([][]byte, 0)
+
+	for _, h := range hashes {
+		for _, k := range s.known {
+			if bytes.Equal(h, k) {
+				res = append(res, h)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (s *validatorStorage) BlockByHash([]byte) (*umid.Block2, error) {
+	return &umid.Block2{CreatedAt: s.parent}, nil
+}
+
+func address(pfx string, key byte) libumi.Address {
+	adr := libumi.NewAddress()
+	adr.SetPrefix(pfx)
+	adr.SetPublicKey(bytes.Repeat([]byte{key}, 32))
+
+	return adr
+}
+
+func basicTx(snd, rcp libumi.Address, val uint64) []byte {
+	tx := libumi.NewTxBasic()
+	tx.SetSender(snd)
+	tx.SetRecipient(rcp)
+	tx.SetValue(val)
+
+	return tx
+}
+
+func structTx(tx libumi.TxStruct, snd libumi.Address, pfx string) []byte {
+	tx.SetSender(snd)
+	tx.SetPrefix(pfx)
+
+	return tx
+}
+
+func addressTx(tx libumi.TxAddress, snd, adr libumi.Address) []byte {
+	tx.SetSender(snd)
+	tx.SetAddress(adr)
+
+	return tx
+}
+
+func newBlock(ts uint32, txs ...[]byte) []byte {
+	blk := libumi.NewBlock()
+	blk.SetTimestamp(ts)
+
+	for _, tx := range txs {
+		blk.AppendTransaction(tx)
+	}
+
+	return blk
+}
+
+func TestValidateBlock(t *testing.T) {
+	alice, bob, carol := address("umi", 1), address("umi", 2), address("umi", 3)
+	master, member := address("umi", 4), address("aaa", 5)
+	profit, fee, dev := address("aaa", 4), address("aaa", 6), address("aaa", 7)
+
+	stored := map[string]*umid.Balance{
+		string(alice):  {Confirmed: 100, Type: "umi"},
+		string(bob):    {Confirmed: 50, Type: "umi"},
+		string(master): {Confirmed: 5_000_000, Type: "umi"},
+		string(dev):    {Type: "dev"},
+		string(profit): {Type: "profit"},
+		string(fee):    {Type: "fee"},
+	}
+
+	structures := map[string]*umid.Structure2{
+		"aaa": {Prefix: "aaa", MasterAddress: master, ProfitAddress: profit, FeeAddress: fee, FeePercent: 100},
+	}
+
+	dup := basicTx(alice, bob, 1)
+
+	tests := []struct {
+		name string
+		txs  [][]byte
+		idx  int
+		err  error
+	}{
+		{"whole balance", [][]byte{basicTx(alice, bob, 100)}, 0, nil},
+		{"insufficient funds", [][]byte{basicTx(alice, bob, 101)}, 0, umid.ErrInsufficientFunds},
+		{"spent twice", [][]byte{basicTx(alice, bob, 60), basicTx(alice, carol, 60)}, 1, umid.ErrInsufficientFunds},
+		{"received in the block", [][]byte{basicTx(alice, bob, 100), basicTx(bob, carol, 150)}, 0, nil},
+		{"received too little", [][]byte{basicTx(alice, bob, 100), basicTx(bob, carol, 151)}, 1, umid.ErrInsufficientFunds},
+		{"unknown structure", [][]byte{basicTx(alice, address("bbb", 1), 1)}, 0, umid.ErrStructureNotFound},
+		{"fee address credited", [][]byte{basicTx(alice, member, 100), basicTx(fee, carol, 100)}, 0, nil},
+		{"profit address credited", [][]byte{basicTx(alice, member, 100), basicTx(profit, carol, 100)}, 0, nil},
+		{"dev address credited", [][]byte{basicTx(alice, member, 100), basicTx(dev, carol, 100)}, 0, nil},
+		{
+			"dev address overspent",
+			[][]byte{basicTx(alice, member, 100), basicTx(dev, carol, 101)}, 1, umid.ErrInsufficientFunds,
+		},
+		{
+			"address change not modelled",
+			[][]byte{addressTx(libumi.NewTxUpdProfitAddr(), master, member), basicTx(member, carol, 1)}, 0, nil,
+		},
+		{"create structure", [][]byte{structTx(libumi.NewTxCrtStruct(), master, "bbb")}, 0, nil},
+		{
+			"create structure without funds",
+			[][]byte{structTx(libumi.NewTxCrtStruct(), alice, "bbb")}, 0, umid.ErrInsufficientFunds,
+		},
+		{"structure exists", [][]byte{structTx(libumi.NewTxCrtStruct(), master, "aaa")}, 0, umid.ErrStructureExists},
+		{
+			"structure created in the block",
+			[][]byte{
+				structTx(libumi.NewTxCrtStruct(), master, "bbb"),
+				structTx(libumi.NewTxUpdStruct(), master, "bbb"),
+			}, 0, nil,
+		},
+		{"update by master", [][]byte{structTx(libumi.NewTxUpdStruct(), master, "aaa")}, 0, nil},
+		{"update by stranger", [][]byte{structTx(libumi.NewTxUpdStruct(), alice, "aaa")}, 0, umid.ErrNotMasterAddress},
+		{
+			"update unknown structure",
+			[][]byte{structTx(libumi.NewTxUpdStruct(), master, "bbb")}, 0, umid.ErrStructureNotFound,
+		},
+		{
+			"address change by stranger",
+			[][]byte{addressTx(libumi.NewTxCrtTransitAddr(), alice, member)}, 0, umid.ErrNotMasterAddress,
+		},
+		{"duplicate in block", [][]byte{dup, dup}, 1, umid.ErrDuplicateTx},
+		{"duplicate in chain", [][]byte{basicTx(alice, bob, 2), basicTx(alice, bob, 3)}, 1, umid.ErrDuplicateTx},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &validatorStorage{balances: stored, structures: structures}
+			known := sha256.Sum256(basicTx(alice, bob, 3))
+			db.known = [][]byte{known[:]}
+
+			bc := blockchain.NewBlockchain().SetStorage(db)
+
+			err := bc.ValidateBlock(newBlock(0, tt.txs...))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			var vErr *umid.ValidationError
+			if errors.As(err, &vErr) && vErr.TxIndex != tt.idx {
+				t.Errorf("expected tx index %d, got %d", tt.idx, vErr.TxIndex)
+			}
+		})
+	}
+}
+
+func TestValidateBlockTimestamp(t *testing.T) {
+	db := &validatorStorage{parent: time.Unix(1000, 0)}
+	bc := blockchain.NewBlockchain().SetStorage(db)
+
+	var vErr *umid.ValidationError
+
+	err := bc.ValidateBlock(newBlock(999))
+	if !errors.Is(err, umid.ErrInvalidTimestamp) || !errors.As(err, &vErr) || vErr.TxIndex != -1 {
+		t.Errorf("expected invalid timestamp, got %v", err)
+	}
+
+	if err := bc.ValidateBlock(newBlock(1000)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
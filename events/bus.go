@@ -0,0 +1,71 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package events
+
+import (
+	"sync"
+	"umid/umid"
+)
+
+const subscriberQueueLen = 256
+
+// Bus ...
+type Bus struct {
+	mu   sync.Mutex
+	subs map[chan *umid.Event]struct{}
+}
+
+// NewBus ...
+func NewBus() *Bus {
+	return &Bus{
+		subs: make(map[chan *umid.Event]struct{}),
+	}
+}
+
+// Publish delivers the event to every subscriber, slow subscribers miss it rather than block the publisher.
+func (b *Bus) Publish(e *umid.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+			break
+		default:
+			break
+		}
+	}
+}
+
+// Subscribe ...
+func (b *Bus) Subscribe() (<-chan *umid.Event, func()) {
+	ch := make(chan *umid.Event, subscriberQueueLen)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subs, ch)
+		b.mu.Unlock()
+	}
+}
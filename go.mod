@@ -6,4 +6,5 @@ require (
 	github.com/gorilla/websocket v1.4.2
 	github.com/jackc/pgx/v4 v4.8.1
 	github.com/umitop/libumi v0.0.0-20200909110620-7c6f42257a92
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 )
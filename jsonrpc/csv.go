@@ -0,0 +1,110 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package jsonrpc
+
+import (
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/umitop/libumi"
+)
+
+const csvPageSize = 1_000
+
+// BalanceHistoryCSV serves the balance history of an address as a CSV statement.
+func (rpc *RPC) BalanceHistoryCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	adr := r.URL.Query().Get("address")
+	if adr == "" {
+		http.Error(w, "address is required", http.StatusBadRequest)
+
+		return
+	}
+
+	if _, err := libumi.NewAddressFromBech32(adr); err != nil {
+		http.Error(w, "invalid address", http.StatusBadRequest)
+
+		return
+	}
+
+	var before *int64
+
+	if s := r.URL.Query().Get("before"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid before", http.StatusBadRequest)
+
+			return
+		}
+
+		before = &n
+	}
+
+	ent, err := rpc.blockchain.BalanceHistory(adr, before, csvPageSize)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+adr+`.csv"`)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"tx_height", "updated_at", "delta", "interest", "balance", "percent", "comment"})
+
+	// the whole history is streamed page by page, each page starts below the last entry of the previous one
+	for {
+		for _, e := range ent {
+			_ = cw.Write([]string{
+				strconv.FormatInt(int64(e.TxHeight), 10),
+				time.Unix(e.UpdatedAt, 0).UTC().Format(time.RFC3339),
+				strconv.FormatInt(e.Delta, 10),
+				strconv.FormatInt(e.Interest, 10),
+				strconv.FormatUint(e.Balance, 10),
+				strconv.FormatUint(uint64(e.Percent), 10),
+				e.Comment,
+			})
+		}
+
+		cw.Flush()
+
+		if len(ent) < csvPageSize {
+			return
+		}
+
+		before = &ent[len(ent)-1].ID
+
+		if ent, err = rpc.blockchain.BalanceHistory(adr, before, csvPageSize); err != nil {
+			// the status is already sent, aborting the response tells the client that the file is incomplete
+			log.Println(err.Error())
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
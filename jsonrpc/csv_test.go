@@ -0,0 +1,122 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package jsonrpc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"umid/jsonrpc"
+	"umid/umid"
+)
+
+type historyMock struct {
+	umid.IBlockchain
+}
+
+func (historyMock) BalanceHistory(string, *int64, int) ([]*umid.BalanceEntry, error) {
+	return []*umid.BalanceEntry{
+		{ID: 3, TxHeight: 2, UpdatedAt: 1600000000, Delta: -50, Interest: 1, Balance: 51, Percent: 800, Comment: "a, b"},
+		{ID: 1, TxHeight: 1, UpdatedAt: 1590000000, Delta: 100, Balance: 100},
+	}, nil
+}
+
+// pagedHistoryMock serves n entries with the ids from n down to 1 and counts the pages requested.
+type pagedHistoryMock struct {
+	umid.IBlockchain
+	n     int64
+	pages int
+}
+
+func (m *pagedHistoryMock) BalanceHistory(_ string, before *int64, limit int) ([]*umid.BalanceEntry, error) {
+	m.pages++
+
+	id := m.n
+	if before != nil && *before <= id {
+		id = *before - 1
+	}
+
+	res := make([]*umid.BalanceEntry, 0, limit)
+
+	for ; id > 0 && len(res) < limit; id-- {
+		res = append(res, &umid.BalanceEntry{ID: id, TxHeight: int32(id)})
+	}
+
+	return res, nil
+}
+
+func TestBalanceHistoryCSVAllPages(t *testing.T) {
+	const adr = "umi1funq869de06x8tdfth0hs5z8v37ral3yyalmuvj7j0z94rtxcaxq289cf9"
+
+	bc := &pagedHistoryMock{n: 2_500}
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+
+	req, _ := http.NewRequest("GET", "/balance-history.csv?address="+adr, nil)
+	res := httptest.NewRecorder()
+	http.HandlerFunc(rpc.BalanceHistoryCSV).ServeHTTP(res, req)
+
+	lines := strings.Split(strings.TrimSuffix(res.Body.String(), "\n"), "\n")
+
+	if len(lines) != 2_501 {
+		t.Fatalf("wrong number of lines: got %d want %d", len(lines), 2_501)
+	}
+
+	if !strings.HasPrefix(lines[1], "2500,") || !strings.HasPrefix(lines[2_500], "1,") {
+		t.Errorf("unexpected rows: first %q, last %q", lines[1], lines[2_500])
+	}
+
+	if bc.pages != 3 {
+		t.Errorf("wrong number of pages: got %d want %d", bc.pages, 3)
+	}
+}
+
+func TestBalanceHistoryCSV(t *testing.T) {
+	const adr = "umi1funq869de06x8tdfth0hs5z8v37ral3yyalmuvj7j0z94rtxcaxq289cf9"
+
+	rpc := jsonrpc.NewRPC().SetBlockchain(historyMock{})
+
+	req, _ := http.NewRequest("GET", "/balance-history.csv?address="+adr, nil)
+	res := httptest.NewRecorder()
+	http.HandlerFunc(rpc.BalanceHistoryCSV).ServeHTTP(res, req)
+
+	exp := "tx_height,updated_at,delta,interest,balance,percent,comment\n" +
+		"2,2020-09-13T12:26:40Z,-50,1,51,800,\"a, b\"\n" +
+		"1,2020-05-20T18:40:00Z,100,0,100,0,\n"
+
+	if res.Code != http.StatusOK {
+		t.Errorf("wrong http code: got %v want %v", res.Code, http.StatusOK)
+	}
+
+	if res.Body.String() != exp {
+		t.Errorf("unexpected body: got %v want %v", res.Body.String(), exp)
+	}
+
+	for _, q := range []string{"", "?address=umi1aaa", "?address=" + adr + "&before=abc"} {
+		req, _ = http.NewRequest("GET", "/balance-history.csv"+q, nil)
+		res = httptest.NewRecorder()
+		http.HandlerFunc(rpc.BalanceHistoryCSV).ServeHTTP(res, req)
+
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("%q: wrong http code: got %v want %v", q, res.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -23,6 +23,7 @@ package jsonrpc
 import (
 	"compress/gzip"
 	"context"
+	"crypto/subtle"
 	"io/ioutil"
 	"net/http"
 	"time"
@@ -155,3 +156,19 @@ func Filter(next func(http.ResponseWriter, *http.Request)) func(http.ResponseWri
 		next(w, r)
 	}
 }
+
+// Bearer rejects requests that do not carry the token in the Authorization header, an empty token rejects all.
+func Bearer(token string, next func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	expected := []byte("Bearer " + token)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -220,3 +220,35 @@ func TestHttpTooManyRequests(t *testing.T) {
 		t.Errorf("unexpected body: got %v want %v", res.Body.String(), expected)
 	}
 }
+
+func TestBearer(t *testing.T) {
+	next := func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	tests := []struct {
+		token  string
+		header string
+		code   int
+	}{
+		{"secret", "Bearer secret", http.StatusOK},
+		{"secret", "", http.StatusUnauthorized},
+		{"secret", "Bearer other", http.StatusUnauthorized},
+		{"secret", "secret", http.StatusUnauthorized},
+		{"", "Bearer ", http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/json-rpc", nil)
+		if test.header != "" {
+			req.Header.Set("Authorization", test.header)
+		}
+
+		res := httptest.NewRecorder()
+		http.HandlerFunc(jsonrpc.Bearer(test.token, next)).ServeHTTP(res, req)
+
+		if res.Code != test.code {
+			t.Errorf("token %q, header %q: got %v want %v", test.token, test.header, res.Code, test.code)
+		}
+	}
+}
@@ -65,28 +65,39 @@ type Method func(bc umid.IBlockchain, params json.RawMessage) (result json.RawMe
 
 // RPC ...
 type RPC struct {
-	blockchain    umid.IBlockchain
-	upgrader      websocket.Upgrader
-	queue         chan rawRequest
-	methods       map[string]Method
-	notifications map[string]func(umid.IBlockchain, json.RawMessage)
+	blockchain umid.IBlockchain
+	bus        umid.IEventBus
+	upgrader   websocket.Upgrader
+	queue      chan rawRequest
+	methods    map[string]Method
 }
 
 // NewRPC ...
 func NewRPC() *RPC {
-	rpc := &RPC{
-		upgrader:      websocket.Upgrader{},
-		queue:         make(chan rawRequest, workerQueueLen),
-		methods:       make(map[string]Method),
-		notifications: make(map[string]func(umid.IBlockchain, json.RawMessage)),
-	}
+	rpc := newRPC()
 
 	rpc.methods["getBalance"] = method.GetBalance{}.Process
+	rpc.methods["getBalanceHistory"] = method.GetBalanceHistory{}.Process
+	rpc.methods["projectBalance"] = method.ProjectBalance{}.Process
+	rpc.methods["listTopAddresses"] = method.ListTopAddresses{}.Process
+	rpc.methods["getNetworkStats"] = method.GetNetworkStats{}.Process
+	rpc.methods["getPeers"] = method.GetPeers{}.Process
+	rpc.methods["listReorgs"] = method.ListReorgs{}.Process
 	rpc.methods["listStructures"] = method.ListStructures{}.Process
 	rpc.methods["getStructure"] = method.GetStructure{}.Process
+	rpc.methods["getStructureHistory"] = method.GetStructureHistory{}.Process
+	rpc.methods["listStructureAddresses"] = method.ListStructureAddresses{}.Process
 	rpc.methods["sendTransaction"] = method.SendTx{}.Process
 	rpc.methods["listTransactions"] = method.ListTxs{}.Process
+	rpc.methods["getTransaction"] = method.GetTx{}.Process
 	rpc.methods["listBlocks"] = method.ListBlocks{}.Process
+	rpc.methods["getBlock"] = method.GetBlock{}.Process
+	rpc.methods["getBlockHeader"] = method.GetBlockHeader{}.Process
+	rpc.methods["getBlockTransactions"] = method.GetBlockTxs{}.Process
+	rpc.methods["getLastBlock"] = method.GetLastBlock{}.Process
+	rpc.methods["getMempoolInfo"] = method.GetMempoolInfo{}.Process
+	rpc.methods["listMempool"] = method.ListMempool{}.Process
+	rpc.methods["getMempoolTransaction"] = method.GetMempoolTx{}.Process
 
 	return rpc
 }
@@ -98,6 +109,32 @@ func (rpc *RPC) SetBlockchain(bc umid.IBlockchain) *RPC {
 	return rpc
 }
 
+// NewWalletRPC returns an RPC with the transaction builder and signer methods only. It must be served on its own
+// listener behind Bearer and never through CORS or the websocket.
+func NewWalletRPC() *RPC {
+	rpc := newRPC()
+
+	rpc.methods["buildTransaction"] = method.BuildTx{}.Process
+	rpc.methods["signAndSendTransaction"] = method.SignAndSendTx{}.Process
+
+	return rpc
+}
+
+func newRPC() *RPC {
+	return &RPC{
+		upgrader: websocket.Upgrader{},
+		queue:    make(chan rawRequest, workerQueueLen),
+		methods:  make(map[string]Method),
+	}
+}
+
+// SetEventBus ...
+func (rpc *RPC) SetEventBus(bus umid.IEventBus) *RPC {
+	rpc.bus = bus
+
+	return rpc
+}
+
 // Worker ...
 func (rpc *RPC) Worker(ctx context.Context, wg *sync.WaitGroup) {
 	wg.Add(1)
@@ -0,0 +1,76 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package method
+
+import (
+	"encoding/json"
+	"umid/umid"
+)
+
+// maxTopOffset keeps deep pages of the rich-list from turning into full scans.
+const maxTopOffset = 10_000
+
+var addressTypes = map[string]bool{
+	"umi": true, "deposit": true, "dev": true, "profit": true, "fee": true, "transit": true,
+}
+
+// ListTopAddresses ...
+type ListTopAddresses struct{}
+
+// Name ...
+func (ListTopAddresses) Name() string {
+	return "listTopAddresses"
+}
+
+// Process ...
+func (ListTopAddresses) Process(bc umid.IBlockchain, params json.RawMessage) (json.RawMessage, json.RawMessage) {
+	prm := &struct {
+		AddressType string `json:"address_type"`
+		Prefix      string `json:"prefix"`
+		Limit       int    `json:"limit"`
+		Offset      int    `json:"offset"`
+	}{
+		Limit: defaultListLimit,
+	}
+
+	if params != nil {
+		if err := json.Unmarshal(params, prm); err != nil {
+			return nil, ErrInvalidParams
+		}
+	}
+
+	if prm.Limit < 1 || prm.Limit > maxListLimit || prm.Offset < 0 || prm.Offset > maxTopOffset {
+		return nil, ErrInvalidParams
+	}
+
+	if (prm.AddressType != "" && !addressTypes[prm.AddressType]) || (prm.Prefix != "" && len(prm.Prefix) != 3) {
+		return nil, ErrInvalidParams
+	}
+
+	adr, err := bc.TopAddresses(umid.AddressFilter{Type: prm.AddressType, Prefix: prm.Prefix}, prm.Limit, prm.Offset)
+	if err != nil {
+		return nil, ErrInternalError
+	}
+
+	res, _ := json.Marshal(adr)
+
+	return res, nil
+}
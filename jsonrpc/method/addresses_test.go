@@ -0,0 +1,114 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package method_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"umid/jsonrpc"
+	"umid/umid"
+)
+
+func TestListTopAddresses(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bc := &bcMock{}
+	bc.FnTopAddresses = func(f umid.AddressFilter, limit int, offset int) ([]*umid.TopAddress, error) {
+		if f.Prefix == "err" {
+			return nil, errors.New("fail")
+		}
+
+		res := []*umid.TopAddress{
+			{Address: "umi1a", Type: "umi", Value: 30, UpdatedAt: 1},
+			{Address: "aaa1b", Type: "deposit", Value: 20, Percent: 100, UpdatedAt: 2},
+		}
+
+		if f.Type == "deposit" {
+			res = res[1:]
+		}
+
+		if offset > 0 || limit < len(res) {
+			res = res[:0]
+		}
+
+		return res, nil
+	}
+
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"listTopAddresses","params":{"limit":0},"id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listTopAddresses","params":{"offset":10001},"id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":2}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listTopAddresses","params":{"address_type":"genesis"},"id":3}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":3}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listTopAddresses","params":{"prefix":"aa"},"id":4}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":4}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listTopAddresses","params":{"prefix":"err"},"id":5}`,
+			`{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":5}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listTopAddresses","id":6}`,
+			`{"jsonrpc":"2.0","result":[{"address":"umi1a","type":"umi","value":30,"percent":0,"updated_at":1},{"address":"aaa1b","type":"deposit","value":20,"percent":100,"updated_at":2}],"id":6}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listTopAddresses","params":{"address_type":"deposit","prefix":"aaa"},"id":7}`,
+			`{"jsonrpc":"2.0","result":[{"address":"aaa1b","type":"deposit","value":20,"percent":100,"updated_at":2}],"id":7}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listTopAddresses","params":{"offset":2},"id":8}`,
+			`{"jsonrpc":"2.0","result":[],"id":8}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
@@ -22,6 +22,8 @@ package method
 
 import (
 	"encoding/json"
+	"errors"
+	"time"
 	"umid/umid"
 )
 
@@ -36,21 +38,103 @@ func (GetBalance) Name() string {
 // Process ...
 func (GetBalance) Process(bc umid.IBlockchain, params json.RawMessage) (result json.RawMessage, error json.RawMessage) {
 	prm := new(struct {
-		Address string `json:"address"`
+		Address  string  `json:"address"`
+		AtTime   *int64  `json:"at_time"`
+		AtHeight *uint32 `json:"at_height"`
 	})
 
 	if err := json.Unmarshal(params, prm); err != nil || prm.Address == "" {
 		return nil, ErrInvalidParams
 	}
 
-	bal, err := bc.Balance(prm.Address)
+	if prm.AtTime != nil && prm.AtHeight != nil {
+		return nil, ErrInvalidParams
+	}
+
+	bal, err := bc.Balance(prm.Address, umid.BalanceAt{Time: unixTime(prm.AtTime), Height: prm.AtHeight})
 	if err != nil {
+		if errors.Is(err, umid.ErrNotFound) {
+			return nil, marshalNotFound("block")
+		}
+
 		return nil, ErrInternalError
 	}
 
 	return marshalBalance(bal), nil
 }
 
+// GetBalanceHistory ...
+type GetBalanceHistory struct{}
+
+// Name ...
+func (GetBalanceHistory) Name() string {
+	return "getBalanceHistory"
+}
+
+// Process ...
+func (GetBalanceHistory) Process(bc umid.IBlockchain, params json.RawMessage) (json.RawMessage, json.RawMessage) {
+	prm := &struct {
+		Address string `json:"address"`
+		Limit   int    `json:"limit"`
+		Before  *int64 `json:"before"`
+	}{
+		Limit: defaultListLimit,
+	}
+
+	if err := json.Unmarshal(params, prm); err != nil || prm.Address == "" {
+		return nil, ErrInvalidParams
+	}
+
+	if prm.Limit < 1 || prm.Limit > maxListLimit {
+		return nil, ErrInvalidParams
+	}
+
+	ent, err := bc.BalanceHistory(prm.Address, prm.Before, prm.Limit)
+	if err != nil {
+		return nil, ErrInternalError
+	}
+
+	res := struct {
+		Entries    []*umid.BalanceEntry `json:"entries"`
+		NextCursor *int64               `json:"next_cursor,omitempty"`
+	}{
+		Entries: ent,
+	}
+
+	if len(ent) == prm.Limit {
+		res.NextCursor = &ent[len(ent)-1].ID
+	}
+
+	return marshalBalance(res), nil
+}
+
+// ProjectBalance ...
+type ProjectBalance struct{}
+
+// Name ...
+func (ProjectBalance) Name() string {
+	return "projectBalance"
+}
+
+// Process ...
+func (ProjectBalance) Process(bc umid.IBlockchain, params json.RawMessage) (json.RawMessage, json.RawMessage) {
+	prm := new(struct {
+		Address string `json:"address"`
+		Until   int64  `json:"until"`
+	})
+
+	if err := json.Unmarshal(params, prm); err != nil || prm.Address == "" || prm.Until <= time.Now().Unix() {
+		return nil, ErrInvalidParams
+	}
+
+	p, err := bc.ProjectBalance(prm.Address, time.Unix(prm.Until, 0))
+	if err != nil {
+		return nil, ErrInternalError
+	}
+
+	return marshalBalance(p), nil
+}
+
 func marshalBalance(v interface{}) json.RawMessage {
 	jsn, _ := json.Marshal(v)
 
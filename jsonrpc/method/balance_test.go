@@ -28,6 +28,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 	"umid/jsonrpc"
 	"umid/umid"
 )
@@ -37,7 +38,16 @@ func TestGetBalance(t *testing.T) {
 	defer cancel()
 
 	bc := &bcMock{}
-	bc.FnBalance = func(a string) (*umid.Balance, error) {
+	bc.FnBalance = func(a string, at umid.BalanceAt) (*umid.Balance, error) {
+		switch {
+		case at.Height != nil && *at.Height == 5:
+			return nil, umid.ErrNotFound
+		case at.Height != nil:
+			return &umid.Balance{Confirmed: uint64(*at.Height)}, nil
+		case at.Time != nil:
+			return &umid.Balance{Confirmed: uint64(at.Time.Unix())}, nil
+		}
+
 		switch a {
 		case "umi1aaa":
 			return &umid.Balance{Confirmed: 1, Interest: 2, Unconfirmed: 3, Composite: nil}, nil
@@ -87,6 +97,22 @@ func TestGetBalance(t *testing.T) {
 			`[{"jsonrpc":"2.0","method":"getBalance","params":{"address":"umi1bbb"},"id":8}, 1]`,
 			`[{"jsonrpc":"2.0","result":{"confirmed":10,"interest":20,"unconfirmed":30,"composite":0,"type":""},"id":8},{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}]`,
 		},
+		{
+			`{"jsonrpc":"2.0","method":"getBalance","params":{"address":"umi1aaa","at_time":1,"at_height":1},"id":9}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":9}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBalance","params":{"address":"umi1aaa","at_time":1600000000},"id":10}`,
+			`{"jsonrpc":"2.0","result":{"confirmed":1600000000,"interest":0,"unconfirmed":0,"type":""},"id":10}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBalance","params":{"address":"umi1aaa","at_height":7},"id":11}`,
+			`{"jsonrpc":"2.0","result":{"confirmed":7,"interest":0,"unconfirmed":0,"type":""},"id":11}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBalance","params":{"address":"umi1aaa","at_height":5},"id":12}`,
+			`{"jsonrpc":"2.0","error":{"code":-32004,"message":"block not found"},"id":12}`,
+		},
 	}
 
 	for _, test := range tests {
@@ -110,3 +136,128 @@ func TestGetBalance(t *testing.T) {
 		}
 	}
 }
+
+func TestGetBalanceHistory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bc := &bcMock{}
+	bc.FnBalanceHistory = func(a string, before *int64, limit int) ([]*umid.BalanceEntry, error) {
+		if a != "umi1aaa" {
+			return nil, errors.New("invalid address")
+		}
+
+		id := int64(20)
+		if before != nil {
+			id = *before - 1
+		}
+
+		// two entries share tx height 10
+		arr := make([]*umid.BalanceEntry, 0, limit)
+		for ; len(arr) < limit && id > 17; id-- {
+			arr = append(arr, &umid.BalanceEntry{
+				ID: id, TxHeight: int32(id+1) / 2, Delta: 100, Interest: 1, Balance: uint64(id-17) * 100,
+			})
+		}
+
+		return arr, nil
+	}
+
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"getBalanceHistory","params":{},"id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBalanceHistory","params":{"address":"umi1aaa","limit":0},"id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":2}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBalanceHistory","params":{"address":"aaa"},"id":3}`,
+			`{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":3}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBalanceHistory","params":{"address":"umi1aaa","limit":2},"id":4}`,
+			`{"jsonrpc":"2.0","result":{"entries":[{"id":20,"tx_height":10,"updated_at":0,"delta":100,"interest":1,"balance":300,"percent":0},{"id":19,"tx_height":10,"updated_at":0,"delta":100,"interest":1,"balance":200,"percent":0}],"next_cursor":19},"id":4}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBalanceHistory","params":{"address":"umi1aaa","limit":5},"id":5}`,
+			`{"jsonrpc":"2.0","result":{"entries":[{"id":20,"tx_height":10,"updated_at":0,"delta":100,"interest":1,"balance":300,"percent":0},{"id":19,"tx_height":10,"updated_at":0,"delta":100,"interest":1,"balance":200,"percent":0},{"id":18,"tx_height":9,"updated_at":0,"delta":100,"interest":1,"balance":100,"percent":0}]},"id":5}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBalanceHistory","params":{"address":"umi1aaa","limit":2,"before":19},"id":6}`,
+			`{"jsonrpc":"2.0","result":{"entries":[{"id":18,"tx_height":9,"updated_at":0,"delta":100,"interest":1,"balance":100,"percent":0}]},"id":6}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
+
+func TestProjectBalance(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bc := &bcMock{}
+	bc.FnProjectBalance = func(a string, until time.Time) (*umid.Projection, error) {
+		if a != "umi1aaa" {
+			return nil, errors.New("invalid address")
+		}
+
+		return &umid.Projection{Value: 100, Projected: 110, Interest: 1000, Until: until.Unix()}, nil
+	}
+
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"projectBalance","params":{"address":"umi1aaa"},"id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"projectBalance","params":{"address":"umi1aaa","until":1},"id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":2}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"projectBalance","params":{"address":"aaa","until":4102444800},"id":3}`,
+			`{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":3}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"projectBalance","params":{"address":"umi1aaa","until":4102444800},"id":4}`,
+			`{"jsonrpc":"2.0","result":{"value":100,"projected":110,"interest":1000,"until":4102444800},"id":4}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
@@ -22,6 +22,7 @@ package method
 
 import (
 	"encoding/json"
+	"errors"
 	"umid/umid"
 )
 
@@ -51,6 +52,147 @@ func (ListBlocks) Process(bc umid.IBlockchain, params json.RawMessage) (result j
 	return marshalBlocks(b), nil
 }
 
+// GetBlock ...
+type GetBlock struct{}
+
+// Name ...
+func (GetBlock) Name() string {
+	return "getBlock"
+}
+
+// Process ...
+func (GetBlock) Process(bc umid.IBlockchain, params json.RawMessage) (result json.RawMessage, error json.RawMessage) {
+	blk, e := blockByParams(bc, params)
+	if e != nil {
+		return nil, e
+	}
+
+	txs, err := bc.BlockTxHashes(blk.Height)
+	if err != nil {
+		return nil, ErrInternalError
+	}
+
+	blk.Transactions = txs
+
+	return marshalBlocks(blk), nil
+}
+
+// GetBlockHeader ...
+type GetBlockHeader struct{}
+
+// Name ...
+func (GetBlockHeader) Name() string {
+	return "getBlockHeader"
+}
+
+// Process ...
+func (GetBlockHeader) Process(bc umid.IBlockchain, params json.RawMessage) (result json.RawMessage, error json.RawMessage) {
+	blk, e := blockByParams(bc, params)
+	if e != nil {
+		return nil, e
+	}
+
+	return marshalBlocks(blk), nil
+}
+
+// GetBlockTxs ...
+type GetBlockTxs struct{}
+
+// Name ...
+func (GetBlockTxs) Name() string {
+	return "getBlockTransactions"
+}
+
+// Process ...
+func (GetBlockTxs) Process(bc umid.IBlockchain, params json.RawMessage) (result json.RawMessage, error json.RawMessage) {
+	prm := &struct {
+		Limit  int `json:"limit"`
+		Offset int `json:"offset"`
+	}{
+		Limit: defaultListLimit,
+	}
+
+	if err := json.Unmarshal(params, prm); err != nil || prm.Limit < 1 || prm.Limit > maxListLimit || prm.Offset < 0 {
+		return nil, ErrInvalidParams
+	}
+
+	blk, e := blockByParams(bc, params)
+	if e != nil {
+		return nil, e
+	}
+
+	txs, err := bc.BlockTransactions(blk.Height, prm.Limit, prm.Offset)
+	if err != nil {
+		return nil, ErrInternalError
+	}
+
+	return marshalTxs(txs), nil
+}
+
+// GetLastBlock ...
+type GetLastBlock struct{}
+
+// Name ...
+func (GetLastBlock) Name() string {
+	return "getLastBlock"
+}
+
+// Process ...
+func (GetLastBlock) Process(bc umid.IBlockchain, _ json.RawMessage) (result json.RawMessage, error json.RawMessage) {
+	n, err := bc.LastBlockHeight()
+	if err != nil {
+		return nil, ErrInternalError
+	}
+
+	blk, err := bc.BlockByHeight(n)
+	if err != nil {
+		if errors.Is(err, umid.ErrNotFound) {
+			return nil, marshalNotFound("block")
+		}
+
+		return nil, ErrInternalError
+	}
+
+	return marshalBlocks(blk), nil
+}
+
+// blockByParams looks the block up by either "height" or "hash", exactly one of them must be set.
+func blockByParams(bc umid.IBlockchain, params json.RawMessage) (*umid.Block, json.RawMessage) {
+	prm := new(struct {
+		Height *uint32 `json:"height"`
+		Hash   string  `json:"hash"`
+	})
+
+	if err := json.Unmarshal(params, prm); err != nil || (prm.Height == nil) == (prm.Hash == "") {
+		return nil, ErrInvalidParams
+	}
+
+	var (
+		blk *umid.Block
+		err error
+	)
+
+	if prm.Height != nil {
+		blk, err = bc.BlockByHeight(*prm.Height)
+	} else {
+		if !isHash(prm.Hash) {
+			return nil, ErrInvalidParams
+		}
+
+		blk, err = bc.BlockByHash(prm.Hash)
+	}
+
+	if err != nil {
+		if errors.Is(err, umid.ErrNotFound) {
+			return nil, marshalNotFound("block")
+		}
+
+		return nil, ErrInternalError
+	}
+
+	return blk, nil
+}
+
 func marshalBlocks(v interface{}) json.RawMessage {
 	jsn, _ := json.Marshal(v)
 
@@ -0,0 +1,127 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package method_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"umid/jsonrpc"
+	"umid/umid"
+)
+
+func TestGetBlock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blk := func() *umid.Block {
+		return &umid.Block{Hash: strings.Repeat("aa", 32), Height: 2, Version: 1, TxCount: 1, Confirmed: true}
+	}
+
+	bc := &bcMock{}
+	bc.FnBlockByHeight = func(n uint32) (*umid.Block, error) {
+		if n == 2 {
+			return blk(), nil
+		}
+
+		return nil, umid.ErrNotFound
+	}
+	bc.FnBlockByHash = func(s string) (*umid.Block, error) {
+		if s == strings.Repeat("aa", 32) {
+			return blk(), nil
+		}
+
+		return nil, umid.ErrNotFound
+	}
+	bc.FnBlockTxHashes = func(n uint32) ([]string, error) {
+		return []string{strings.Repeat("00", 32)}, nil
+	}
+	bc.FnBlockTransactions = func(n uint32, limit, offset int) ([]*umid.Transaction, error) {
+		return []*umid.Transaction{{Hash: strings.Repeat("00", 32), BlockHeight: int32(n)}}, nil
+	}
+	bc.FnLastBlockHeight = func() (uint32, error) {
+		return 2, nil
+	}
+
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	header := `{"hash":"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa","height":2,"version":1,` +
+		`"merkle_root_hash":"","timestamp":0,"tx_count":1,"public_key":"","confirmed":true`
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"getBlock","id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBlock","params":{},"id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":2}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBlock","params":{"height":2,"hash":"aa"},"id":3}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":3}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBlock","params":{"height":3},"id":4}`,
+			`{"jsonrpc":"2.0","error":{"code":-32004,"message":"block not found"},"id":4}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBlock","params":{"height":2},"id":5}`,
+			`{"jsonrpc":"2.0","result":` + header + `,"transactions":["0000000000000000000000000000000000000000000000000000000000000000"]},"id":5}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBlockHeader","params":{"hash":"` + strings.Repeat("aa", 32) + `"},"id":6}`,
+			`{"jsonrpc":"2.0","result":` + header + `},"id":6}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBlockTransactions","params":{"height":2,"limit":0},"id":7}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":7}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getBlockTransactions","params":{"height":2},"id":8}`,
+			`{"jsonrpc":"2.0","result":[{"hash":"0000000000000000000000000000000000000000000000000000000000000000","block_height":2,"block_tx_idx":0,"version":0,"sender":""}],"id":8}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getLastBlock","id":9}`,
+			`{"jsonrpc":"2.0","result":` + header + `},"id":9}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
@@ -20,10 +20,18 @@
 
 package method
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"umid/umid"
+)
 
 const (
-	codeInvalidParams = -32602
+	codeInvalidParams     = -32602
+	codeTxConfirmed       = -32001
+	codeInsufficientFunds = -32002
+	codeNonceReplay       = -32003
+	codeNotFound          = -32004
 )
 
 // Errors.
@@ -43,3 +51,20 @@ func marshalError(code int, message string) json.RawMessage {
 
 	return jsn
 }
+
+func marshalNotFound(what string) json.RawMessage {
+	return marshalError(codeNotFound, what+" not found")
+}
+
+func marshalTxError(err error) json.RawMessage {
+	switch {
+	case errors.Is(err, umid.ErrTxConfirmed):
+		return marshalError(codeTxConfirmed, err.Error())
+	case errors.Is(err, umid.ErrInsufficientFunds):
+		return marshalError(codeInsufficientFunds, err.Error())
+	case errors.Is(err, umid.ErrNonceReplay):
+		return marshalError(codeNonceReplay, err.Error())
+	}
+
+	return marshalError(codeInvalidParams, err.Error())
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package method
+
+import (
+	"encoding/json"
+	"errors"
+	"umid/umid"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// GetMempoolInfo ...
+type GetMempoolInfo struct{}
+
+// Name ...
+func (GetMempoolInfo) Name() string {
+	return "getMempoolInfo"
+}
+
+// Process ...
+func (GetMempoolInfo) Process(bc umid.IBlockchain, _ json.RawMessage) (result json.RawMessage, error json.RawMessage) {
+	inf, err := bc.MempoolInfo()
+	if err != nil {
+		return nil, ErrInternalError
+	}
+
+	result, _ = json.Marshal(inf)
+
+	return result, nil
+}
+
+// ListMempool ...
+type ListMempool struct{}
+
+// Name ...
+func (ListMempool) Name() string {
+	return "listMempool"
+}
+
+// Process ...
+func (ListMempool) Process(bc umid.IBlockchain, params json.RawMessage) (result json.RawMessage, error json.RawMessage) {
+	prm := &struct {
+		Limit  int `json:"limit"`
+		Offset int `json:"offset"`
+	}{
+		Limit: defaultListLimit,
+	}
+
+	if params != nil {
+		if err := json.Unmarshal(params, prm); err != nil {
+			return nil, ErrInvalidParams
+		}
+	}
+
+	if prm.Limit < 1 || prm.Limit > maxListLimit || prm.Offset < 0 {
+		return nil, ErrInvalidParams
+	}
+
+	txs, err := bc.MempoolTransactions(prm.Limit, prm.Offset)
+	if err != nil {
+		return nil, ErrInternalError
+	}
+
+	return marshalTxs(txs), nil
+}
+
+// GetMempoolTx ...
+type GetMempoolTx struct{}
+
+// Name ...
+func (GetMempoolTx) Name() string {
+	return "getMempoolTransaction"
+}
+
+// Process ...
+func (GetMempoolTx) Process(bc umid.IBlockchain, params json.RawMessage) (result json.RawMessage, error json.RawMessage) {
+	prm := new(struct {
+		Hash string `json:"hash"`
+	})
+
+	if err := json.Unmarshal(params, prm); err != nil || !isHash(prm.Hash) {
+		return nil, ErrInvalidParams
+	}
+
+	tx, err := bc.MempoolTransaction(prm.Hash)
+	if err != nil {
+		if errors.Is(err, umid.ErrNotFound) {
+			return nil, marshalNotFound("transaction")
+		}
+
+		return nil, ErrInternalError
+	}
+
+	return marshalTxs(tx), nil
+}
+
+func isHash(s string) bool {
+	const hashLength = 64
+
+	if len(s) != hashLength {
+		return false
+	}
+
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package method_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"umid/jsonrpc"
+	"umid/umid"
+)
+
+func TestGetMempoolInfo(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bc := &bcMock{}
+	bc.FnMempoolInfo = func() (*umid.MempoolInfo, error) {
+		return &umid.MempoolInfo{Count: 2, Bytes: 300, OldestAt: 1600000000}, nil
+	}
+
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"getMempoolInfo","id":1}`,
+			`{"jsonrpc":"2.0","result":{"count":2,"bytes":300,"oldest_at":1600000000,"counters":{"confirmed":0,"expired":0,"evicted":0}},"id":1}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
+
+func TestListMempool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bc := &bcMock{}
+	bc.FnMempoolTransactions = func(limit, offset int) ([]*umid.Transaction, error) {
+		if offset > 0 {
+			return make([]*umid.Transaction, 0), nil
+		}
+
+		pfx, name, prc := "aaa", "Test", uint16(500)
+
+		arr := make([]*umid.Transaction, 2)
+		arr[0] = &umid.Transaction{Hash: strings.Repeat("00", 32), CreatedAt: 1600000000, Version: 1}
+		arr[1] = &umid.Transaction{
+			Hash: strings.Repeat("11", 32), CreatedAt: 1600000001, Version: 3,
+			Structure: &umid.TxStruct{Prefix: &pfx, Name: &name, ProfitPercent: &prc, FeePercent: &prc},
+		}
+
+		return arr, nil
+	}
+
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"listMempool","params":[],"id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listMempool","params":{"limit":0},"id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":2}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listMempool","params":{"limit":1001},"id":3}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":3}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listMempool","params":{"offset":-1},"id":4}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":4}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listMempool","id":5}`,
+			`{"jsonrpc":"2.0","result":[{"hash":"0000000000000000000000000000000000000000000000000000000000000000","created_at":1600000000,"block_height":0,"block_tx_idx":0,"version":1,"sender":""},{"hash":"1111111111111111111111111111111111111111111111111111111111111111","created_at":1600000001,"block_height":0,"block_tx_idx":0,"version":3,"sender":"","structure":{"prefix":"aaa","name":"Test","profit_percent":500,"fee_percent":500}}],"id":5}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listMempool","params":{"limit":10,"offset":10},"id":6}`,
+			`{"jsonrpc":"2.0","result":[],"id":6}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
+
+func TestGetMempoolTransaction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bc := &bcMock{}
+	bc.FnMempoolTransaction = func(s string) (*umid.Transaction, error) {
+		switch s {
+		case strings.Repeat("00", 32):
+			return &umid.Transaction{Hash: s, CreatedAt: 1600000000, Version: 1}, nil
+		case strings.Repeat("11", 32):
+			return nil, umid.ErrNotFound
+		}
+
+		return nil, errors.New("db error")
+	}
+
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"getMempoolTransaction","id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getMempoolTransaction","params":{"hash":"00"},"id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":2}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getMempoolTransaction","params":{"hash":"` + strings.Repeat("zz", 32) + `"},"id":3}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":3}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getMempoolTransaction","params":{"hash":"` + strings.Repeat("00", 32) + `"},"id":4}`,
+			`{"jsonrpc":"2.0","result":{"hash":"0000000000000000000000000000000000000000000000000000000000000000","created_at":1600000000,"block_height":0,"block_tx_idx":0,"version":1,"sender":""},"id":4}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getMempoolTransaction","params":{"hash":"` + strings.Repeat("11", 32) + `"},"id":5}`,
+			`{"jsonrpc":"2.0","error":{"code":-32004,"message":"transaction not found"},"id":5}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getMempoolTransaction","params":{"hash":"` + strings.Repeat("22", 32) + `"},"id":6}`,
+			`{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":6}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
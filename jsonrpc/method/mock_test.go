@@ -20,22 +20,53 @@
 
 package method_test
 
-import "umid/umid"
+import (
+	"time"
+	"umid/umid"
+)
 
 type bcMock struct {
-	FnBalance               func(string) (*umid.Balance, error)
+	FnBalance               func(string, umid.BalanceAt) (*umid.Balance, error)
+	FnBalanceHistory        func(string, *int64, int) ([]*umid.BalanceEntry, error)
+	FnProjectBalance        func(string, time.Time) (*umid.Projection, error)
 	FnAddTransaction        func([]byte) error
 	FnStructureByPrefix     func(string) (*umid.Structure, error)
 	FnStructures            func() ([]*umid.Structure, error)
-	FnTransactionsByAddress func(string) ([]*umid.Transaction, error)
+	FnStructureHistory      func(string, *int32, int) (*umid.StructureHistory, error)
+	FnStructureAddresses    func(string) ([]*umid.StructureAddress, error)
+	FnTransactionsByAddress func(string, umid.TxFilter) ([]*umid.Transaction, error)
+	FnTopAddresses          func(umid.AddressFilter, int, int) ([]*umid.TopAddress, error)
+	FnNetworkStats          func(int) (*umid.NetworkStats, error)
+	FnBuildTransaction      func(umid.TxDraft) ([]byte, error)
+	FnSignTransaction       func([]byte) ([]byte, error)
+	FnPeers                 func(int) ([]*umid.Peer, error)
+	FnSavePeers             func([]*umid.Peer) error
+	FnReorganize            func(uint32, [][]byte, string) error
+	FnReorgs                func(int) ([]*umid.Reorg, error)
 	FnAddBlock              func([]byte) error
 	FnLastBlockHeight       func() (uint32, error)
 	FnBlocksByHeight        func(uint64) ([][]byte, error)
 	FnMempool               func() (umid.IMempool, error)
+	FnBlockByHeight         func(uint32) (*umid.Block, error)
+	FnBlockByHash           func(string) (*umid.Block, error)
+	FnBlockTxHashes         func(uint32) ([]string, error)
+	FnBlockTransactions     func(uint32, int, int) ([]*umid.Transaction, error)
+	FnMempoolInfo           func() (*umid.MempoolInfo, error)
+	FnMempoolTransactions   func(int, int) ([]*umid.Transaction, error)
+	FnMempoolTransaction    func(string) (*umid.Transaction, error)
+	FnTransaction           func(string) (*umid.Transaction, error)
 }
 
-func (m *bcMock) Balance(s string) (*umid.Balance, error) {
-	return m.FnBalance(s)
+func (m *bcMock) Balance(s string, at umid.BalanceAt) (*umid.Balance, error) {
+	return m.FnBalance(s, at)
+}
+
+func (m *bcMock) BalanceHistory(s string, before *int64, limit int) ([]*umid.BalanceEntry, error) {
+	return m.FnBalanceHistory(s, before, limit)
+}
+
+func (m *bcMock) ProjectBalance(s string, until time.Time) (*umid.Projection, error) {
+	return m.FnProjectBalance(s, until)
 }
 
 func (m *bcMock) AddTransaction(b []byte) error {
@@ -50,8 +81,16 @@ func (m *bcMock) Structures() ([]*umid.Structure, error) {
 	return m.FnStructures()
 }
 
-func (m *bcMock) TransactionsByAddress(s string) ([]*umid.Transaction, error) {
-	return m.FnTransactionsByAddress(s)
+func (m *bcMock) StructureHistory(p string, before *int32, limit int) (*umid.StructureHistory, error) {
+	return m.FnStructureHistory(p, before, limit)
+}
+
+func (m *bcMock) StructureAddresses(p string) ([]*umid.StructureAddress, error) {
+	return m.FnStructureAddresses(p)
+}
+
+func (m *bcMock) TransactionsByAddress(s string, f umid.TxFilter) ([]*umid.Transaction, error) {
+	return m.FnTransactionsByAddress(s, f)
 }
 
 func (m *bcMock) LastBlockHeight() (uint32, error) {
@@ -69,3 +108,67 @@ func (m *bcMock) BlocksByHeight(n uint64) ([][]byte, error) {
 func (m *bcMock) Mempool() (umid.IMempool, error) {
 	return m.FnMempool()
 }
+
+func (m *bcMock) MempoolInfo() (*umid.MempoolInfo, error) {
+	return m.FnMempoolInfo()
+}
+
+func (m *bcMock) MempoolTransactions(limit, offset int) ([]*umid.Transaction, error) {
+	return m.FnMempoolTransactions(limit, offset)
+}
+
+func (m *bcMock) MempoolTransaction(s string) (*umid.Transaction, error) {
+	return m.FnMempoolTransaction(s)
+}
+
+func (m *bcMock) Transaction(s string) (*umid.Transaction, error) {
+	return m.FnTransaction(s)
+}
+
+func (m *bcMock) BlockByHeight(n uint32) (*umid.Block, error) {
+	return m.FnBlockByHeight(n)
+}
+
+func (m *bcMock) BlockByHash(s string) (*umid.Block, error) {
+	return m.FnBlockByHash(s)
+}
+
+func (m *bcMock) BlockTxHashes(n uint32) ([]string, error) {
+	return m.FnBlockTxHashes(n)
+}
+
+func (m *bcMock) BlockTransactions(n uint32, limit, offset int) ([]*umid.Transaction, error) {
+	return m.FnBlockTransactions(n, limit, offset)
+}
+
+func (m *bcMock) TopAddresses(f umid.AddressFilter, limit int, offset int) ([]*umid.TopAddress, error) {
+	return m.FnTopAddresses(f, limit, offset)
+}
+
+func (m *bcMock) NetworkStats(days int) (*umid.NetworkStats, error) {
+	return m.FnNetworkStats(days)
+}
+
+func (m *bcMock) BuildTransaction(d umid.TxDraft) ([]byte, error) {
+	return m.FnBuildTransaction(d)
+}
+
+func (m *bcMock) SignTransaction(b []byte) ([]byte, error) {
+	return m.FnSignTransaction(b)
+}
+
+func (m *bcMock) Peers(limit int) ([]*umid.Peer, error) {
+	return m.FnPeers(limit)
+}
+
+func (m *bcMock) SavePeers(p []*umid.Peer) error {
+	return m.FnSavePeers(p)
+}
+
+func (m *bcMock) Reorganize(fork uint32, blocks [][]byte, peer string) error {
+	return m.FnReorganize(fork, blocks, peer)
+}
+
+func (m *bcMock) Reorgs(limit int) ([]*umid.Reorg, error) {
+	return m.FnReorgs(limit)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package method
+
+import (
+	"encoding/json"
+	"umid/umid"
+)
+
+// ListReorgs ...
+type ListReorgs struct{}
+
+// Name ...
+func (ListReorgs) Name() string {
+	return "listReorgs"
+}
+
+// Process ...
+func (ListReorgs) Process(bc umid.IBlockchain, params json.RawMessage) (json.RawMessage, json.RawMessage) {
+	prm := &struct {
+		Limit int `json:"limit"`
+	}{
+		Limit: defaultListLimit,
+	}
+
+	if params != nil {
+		if err := json.Unmarshal(params, prm); err != nil {
+			return nil, ErrInvalidParams
+		}
+	}
+
+	if prm.Limit < 1 || prm.Limit > maxListLimit {
+		return nil, ErrInvalidParams
+	}
+
+	reorgs, err := bc.Reorgs(prm.Limit)
+	if err != nil {
+		return nil, ErrInternalError
+	}
+
+	res, _ := json.Marshal(reorgs)
+
+	return res, nil
+}
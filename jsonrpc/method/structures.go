@@ -22,6 +22,7 @@ package method
 
 import (
 	"encoding/json"
+	"errors"
 	"umid/umid"
 )
 
@@ -77,6 +78,85 @@ func (ListStructures) Process(bc umid.IBlockchain, _ json.RawMessage) (result js
 	return
 }
 
+// GetStructureHistory ...
+type GetStructureHistory struct{}
+
+// Name ...
+func (GetStructureHistory) Name() string {
+	return "getStructureHistory"
+}
+
+// Process ...
+func (GetStructureHistory) Process(bc umid.IBlockchain, params json.RawMessage) (json.RawMessage, json.RawMessage) {
+	prm := &struct {
+		Prefix       string `json:"prefix"`
+		Limit        int    `json:"limit"`
+		BeforeHeight *int32 `json:"before_height"`
+	}{
+		Limit: defaultListLimit,
+	}
+
+	if err := json.Unmarshal(params, prm); err != nil || len(prm.Prefix) != 3 {
+		return nil, ErrInvalidParams
+	}
+
+	if prm.Limit < 1 || prm.Limit > maxListLimit {
+		return nil, ErrInvalidParams
+	}
+
+	h, err := bc.StructureHistory(prm.Prefix, prm.BeforeHeight, prm.Limit)
+	if err != nil {
+		if errors.Is(err, umid.ErrNotFound) {
+			return nil, marshalNotFound("structure")
+		}
+
+		return nil, ErrInternalError
+	}
+
+	res := struct {
+		*umid.StructureHistory
+		NextCursor *int32 `json:"next_cursor,omitempty"`
+	}{
+		StructureHistory: h,
+	}
+
+	if len(h.Balances) == prm.Limit {
+		res.NextCursor = &h.Balances[len(h.Balances)-1].TxHeight
+	}
+
+	return marshalStructure(res), nil
+}
+
+// ListStructureAddresses ...
+type ListStructureAddresses struct{}
+
+// Name ...
+func (ListStructureAddresses) Name() string {
+	return "listStructureAddresses"
+}
+
+// Process ...
+func (ListStructureAddresses) Process(bc umid.IBlockchain, params json.RawMessage) (json.RawMessage, json.RawMessage) {
+	prm := new(struct {
+		Prefix string `json:"prefix"`
+	})
+
+	if err := json.Unmarshal(params, prm); err != nil || len(prm.Prefix) != 3 {
+		return nil, ErrInvalidParams
+	}
+
+	a, err := bc.StructureAddresses(prm.Prefix)
+	if err != nil {
+		if errors.Is(err, umid.ErrNotFound) {
+			return nil, marshalNotFound("structure")
+		}
+
+		return nil, ErrInternalError
+	}
+
+	return marshalStructure(a), nil
+}
+
 func marshalStructure(v interface{}) json.RawMessage {
 	jsn, _ := json.Marshal(v)
 
@@ -167,3 +167,129 @@ func TestListStructures(t *testing.T) {
 		}
 	}
 }
+
+func TestGetStructureHistory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bc := &bcMock{}
+	bc.FnStructureHistory = func(p string, before *int32, limit int) (*umid.StructureHistory, error) {
+		switch p {
+		case "umi":
+			return nil, umid.ErrNotFound
+		case "aaa":
+			return nil, errors.New("fail")
+		}
+
+		h := &umid.StructureHistory{
+			Levels:   []*umid.StructureLevel{{Level: 1, Percent: 100, BlockHeight: 2, UpdatedAt: 3}},
+			Settings: []*umid.StructureSettings{},
+			Balances: []*umid.StructureBalance{{Value: 5, TxHeight: 7, UpdatedAt: 8}},
+		}
+
+		if before != nil || limit != 1 {
+			h.Balances = h.Balances[:0]
+		}
+
+		return h, nil
+	}
+
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"getStructureHistory","params":{"prefix":"aa"},"id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getStructureHistory","params":{"prefix":"abc","limit":0},"id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":2}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getStructureHistory","params":{"prefix":"umi"},"id":3}`,
+			`{"jsonrpc":"2.0","error":{"code":-32004,"message":"structure not found"},"id":3}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getStructureHistory","params":{"prefix":"aaa"},"id":4}`,
+			`{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":4}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getStructureHistory","params":{"prefix":"abc","limit":1},"id":5}`,
+			`{"jsonrpc":"2.0","result":{"levels":[{"level":1,"percent":100,"dev_percent":0,"profit_percent":0,"deposit_percent":0,"block_height":2,"updated_at":3}],"settings":[],"balances":[{"value":5,"percent":0,"tx_height":7,"updated_at":8}],"next_cursor":7},"id":5}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getStructureHistory","params":{"prefix":"abc","limit":1,"before_height":7},"id":6}`,
+			`{"jsonrpc":"2.0","result":{"levels":[{"level":1,"percent":100,"dev_percent":0,"profit_percent":0,"deposit_percent":0,"block_height":2,"updated_at":3}],"settings":[],"balances":[]},"id":6}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
+
+func TestListStructureAddresses(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deleted := int32(9)
+
+	bc := &bcMock{}
+	bc.FnStructureAddresses = func(p string) ([]*umid.StructureAddress, error) {
+		if p != "abc" {
+			return nil, umid.ErrNotFound
+		}
+
+		return []*umid.StructureAddress{
+			{Address: "abc1dev", Type: "dev", CreatedTxHeight: 1, CreatedAt: 2},
+			{Address: "abc1tr", Type: "transit", CreatedTxHeight: 3, DeletedTxHeight: &deleted, CreatedAt: 4},
+		}, nil
+	}
+
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"listStructureAddresses","params":{},"id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listStructureAddresses","params":{"prefix":"umi"},"id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32004,"message":"structure not found"},"id":2}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listStructureAddresses","params":{"prefix":"abc"},"id":3}`,
+			`{"jsonrpc":"2.0","result":[{"address":"abc1dev","type":"dev","created_tx_height":1,"created_at":2},{"address":"abc1tr","type":"transit","created_tx_height":3,"deleted_tx_height":9,"created_at":4}],"id":3}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
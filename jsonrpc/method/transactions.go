@@ -24,6 +24,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"time"
 	"umid/umid"
 )
 
@@ -37,20 +39,118 @@ func (ListTxs) Name() string {
 
 // Process ...
 func (ListTxs) Process(bc umid.IBlockchain, params json.RawMessage) (result json.RawMessage, error json.RawMessage) {
+	prm := &struct {
+		Address      string `json:"address"`
+		Limit        *int   `json:"limit"`
+		BeforeHeight *int32 `json:"before_height"`
+		AfterHeight  *int32 `json:"after_height"`
+		Direction    string `json:"direction"`
+		Version      *int16 `json:"version"`
+		FromTime     *int64 `json:"from_time"`
+		ToTime       *int64 `json:"to_time"`
+	}{}
+
+	if err := json.Unmarshal(params, prm); err != nil || prm.Address == "" {
+		return nil, ErrInvalidParams
+	}
+
+	// without pagination params the response stays a plain array for existing clients
+	paged := prm.Limit != nil || prm.BeforeHeight != nil || prm.AfterHeight != nil
+
+	limit := defaultListLimit
+	if prm.Limit != nil {
+		limit = *prm.Limit
+	}
+
+	if !validTxFilter(limit, prm.BeforeHeight, prm.AfterHeight, prm.Direction) {
+		return nil, ErrInvalidParams
+	}
+
+	f := umid.TxFilter{
+		Limit:        limit,
+		BeforeHeight: prm.BeforeHeight,
+		AfterHeight:  prm.AfterHeight,
+		Direction:    prm.Direction,
+		Version:      prm.Version,
+		FromTime:     unixTime(prm.FromTime),
+		ToTime:       unixTime(prm.ToTime),
+	}
+
+	txs, err := bc.TransactionsByAddress(prm.Address, f)
+	if err != nil {
+		return nil, ErrInternalError
+	}
+
+	if !paged {
+		return marshalTxs(txs), nil
+	}
+
+	res := struct {
+		Transactions []*umid.Transaction `json:"transactions"`
+		NextCursor   *int32              `json:"next_cursor,omitempty"`
+	}{
+		Transactions: txs,
+	}
+
+	// a full page means there may be more, the client continues from the last height
+	if len(txs) == limit {
+		res.NextCursor = &txs[len(txs)-1].Height
+	}
+
+	return marshalTxs(res), nil
+}
+
+func validTxFilter(limit int, before, after *int32, direction string) bool {
+	if limit < 1 || limit > maxListLimit || (before != nil && after != nil) {
+		return false
+	}
+
+	switch direction {
+	case "", umid.TxDirectionSent, umid.TxDirectionReceived, umid.TxDirectionFee:
+		return true
+	}
+
+	return false
+}
+
+func unixTime(sec *int64) *time.Time {
+	if sec == nil {
+		return nil
+	}
+
+	t := time.Unix(*sec, 0)
+
+	return &t
+}
+
+// GetTx ...
+type GetTx struct{}
+
+// Name ...
+func (GetTx) Name() string {
+	return "getTransaction"
+}
+
+// Process ...
+func (GetTx) Process(bc umid.IBlockchain, params json.RawMessage) (result json.RawMessage, error json.RawMessage) {
 	prm := new(struct {
-		Address string `json:"address"`
+		Hash string `json:"hash"`
 	})
 
-	if err := json.Unmarshal(params, prm); err != nil || prm.Address == "" {
+	if err := json.Unmarshal(params, prm); err != nil || !isHash(prm.Hash) {
 		return nil, ErrInvalidParams
 	}
 
-	txs, err := bc.TransactionsByAddress(prm.Address)
+	tx, err := bc.Transaction(prm.Hash)
 	if err != nil {
+		if errors.Is(err, umid.ErrNotFound) {
+			return nil, marshalNotFound("transaction")
+		}
+
 		return nil, ErrInternalError
 	}
 
-	return marshalTxs(txs), nil
+	return marshalTxs(tx), nil
 }
 
 func marshalTxs(v interface{}) json.RawMessage {
@@ -78,7 +178,7 @@ func (SendTx) Process(bc umid.IBlockchain, params json.RawMessage) (result json.
 	}
 
 	if err := bc.AddTransaction(prm.Tx); err != nil {
-		return nil, marshalError(codeInvalidParams, err.Error())
+		return nil, marshalTxError(err)
 	}
 
 	hash := sha256.Sum256(prm.Tx)
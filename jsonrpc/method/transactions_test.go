@@ -35,7 +35,7 @@ import (
 
 func TestListTransaction(t *testing.T) {
 	bc := &bcMock{}
-	bc.FnTransactionsByAddress = func(s string) ([]*umid.Transaction, error) {
+	bc.FnTransactionsByAddress = func(s string, f umid.TxFilter) ([]*umid.Transaction, error) {
 		if s == "umi1aaa" {
 			return make([]*umid.Transaction, 0), nil
 		}
@@ -47,6 +47,15 @@ func TestListTransaction(t *testing.T) {
 			return arr, nil
 		}
 
+		if s == "umi1ccc" && f.Direction == umid.TxDirectionSent && *f.BeforeHeight == 100 {
+			arr := make([]*umid.Transaction, f.Limit)
+			for i := range arr {
+				arr[i] = &umid.Transaction{Hash: strings.Repeat("00", 32), Height: int32(99 - i), Version: 1}
+			}
+
+			return arr, nil
+		}
+
 		return nil, errors.New("invalid address")
 	}
 
@@ -92,6 +101,26 @@ func TestListTransaction(t *testing.T) {
 			`[{"jsonrpc":"2.0","method":"listTransactions","params":{"address":"umi1aaa"},"id":8}, 1]`,
 			`[{"jsonrpc":"2.0","result":[],"id":8},{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}]`,
 		},
+		{
+			`{"jsonrpc":"2.0","method":"listTransactions","params":{"address":"umi1aaa","limit":0},"id":9}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":9}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listTransactions","params":{"address":"umi1aaa","direction":"abc"},"id":10}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":10}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listTransactions","params":{"address":"umi1aaa","before_height":1,"after_height":1},"id":11}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":11}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listTransactions","params":{"address":"umi1ccc","limit":2,"before_height":100,"direction":"sent"},"id":12}`,
+			`{"jsonrpc":"2.0","result":{"transactions":[{"hash":"0000000000000000000000000000000000000000000000000000000000000000","height":99,"block_height":0,"block_tx_idx":0,"version":1,"sender":""},{"hash":"0000000000000000000000000000000000000000000000000000000000000000","height":98,"block_height":0,"block_tx_idx":0,"version":1,"sender":""}],"next_cursor":98},"id":12}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"listTransactions","params":{"address":"umi1aaa","limit":10},"id":13}`,
+			`{"jsonrpc":"2.0","result":{"transactions":[]},"id":13}`,
+		},
 	}
 
 	for _, test := range tests {
@@ -122,8 +151,15 @@ func TestSendTransaction(t *testing.T) {
 
 	bc := &bcMock{}
 	bc.FnAddTransaction = func(s []byte) error {
-		if bytes.Equal(make([]byte, 150), s) {
+		switch {
+		case bytes.Equal(make([]byte, 150), s):
 			return errors.New("invalid transaction")
+		case bytes.Equal(bytes.Repeat([]byte{1}, 150), s):
+			return umid.ErrTxConfirmed
+		case bytes.Equal(bytes.Repeat([]byte{2}, 150), s):
+			return umid.ErrInsufficientFunds
+		case bytes.Equal(bytes.Repeat([]byte{3}, 150), s):
+			return umid.ErrNonceReplay
 		}
 
 		return nil
@@ -170,6 +206,18 @@ func TestSendTransaction(t *testing.T) {
 			`{"jsonrpc":"2.0","method":"sendTransaction","params":{"base64":"!AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"},"id":8}`,
 			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":8}`,
 		},
+		{
+			`{"jsonrpc":"2.0","method":"sendTransaction","params":{"base64":"AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEB"},"id":9}`,
+			`{"jsonrpc":"2.0","error":{"code":-32001,"message":"transaction already confirmed"},"id":9}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"sendTransaction","params":{"base64":"AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgIC"},"id":10}`,
+			`{"jsonrpc":"2.0","error":{"code":-32002,"message":"insufficient funds"},"id":10}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"sendTransaction","params":{"base64":"AwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMD"},"id":11}`,
+			`{"jsonrpc":"2.0","error":{"code":-32003,"message":"nonce already used"},"id":11}`,
+		},
 	}
 
 	for _, test := range tests {
@@ -193,3 +241,68 @@ func TestSendTransaction(t *testing.T) {
 		}
 	}
 }
+
+func TestGetTransaction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bc := &bcMock{}
+	bc.FnTransaction = func(s string) (*umid.Transaction, error) {
+		switch s {
+		case strings.Repeat("00", 32):
+			return &umid.Transaction{Hash: s, Status: umid.TxStatusConfirmed, Confirmations: 3, BlockHeight: 10}, nil
+		case strings.Repeat("11", 32):
+			return &umid.Transaction{Hash: s, Status: umid.TxStatusPending, CreatedAt: 1600000000}, nil
+		case strings.Repeat("22", 32):
+			return nil, umid.ErrNotFound
+		}
+
+		return nil, errors.New("db error")
+	}
+
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"getTransaction","id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getTransaction","params":{"hash":"abc"},"id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":2}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getTransaction","params":{"hash":"` + strings.Repeat("00", 32) + `"},"id":3}`,
+			`{"jsonrpc":"2.0","result":{"hash":"0000000000000000000000000000000000000000000000000000000000000000","status":"confirmed","confirmations":3,"block_height":10,"block_tx_idx":0,"version":0,"sender":""},"id":3}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getTransaction","params":{"hash":"` + strings.Repeat("11", 32) + `"},"id":4}`,
+			`{"jsonrpc":"2.0","result":{"hash":"1111111111111111111111111111111111111111111111111111111111111111","status":"pending","created_at":1600000000,"block_height":0,"block_tx_idx":0,"version":0,"sender":""},"id":4}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getTransaction","params":{"hash":"` + strings.Repeat("22", 32) + `"},"id":5}`,
+			`{"jsonrpc":"2.0","error":{"code":-32004,"message":"transaction not found"},"id":5}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"getTransaction","params":{"hash":"` + strings.Repeat("33", 32) + `"},"id":6}`,
+			`{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error"},"id":6}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
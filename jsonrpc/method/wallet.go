@@ -0,0 +1,123 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package method
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"umid/umid"
+)
+
+// BuildTx ...
+type BuildTx struct{}
+
+// Name ...
+func (BuildTx) Name() string {
+	return "buildTransaction"
+}
+
+// Process ...
+func (BuildTx) Process(bc umid.IBlockchain, params json.RawMessage) (json.RawMessage, json.RawMessage) {
+	prm := new(struct {
+		Version       *uint8 `json:"version"`
+		Sender        string `json:"sender"`
+		Recipient     string `json:"recipient"`
+		Value         uint64 `json:"value"`
+		Prefix        string `json:"prefix"`
+		Name          string `json:"name"`
+		ProfitPercent uint16 `json:"profit_percent"`
+		FeePercent    uint16 `json:"fee_percent"`
+		Address       string `json:"address"`
+	})
+
+	if err := json.Unmarshal(params, prm); err != nil || prm.Version == nil || prm.Sender == "" {
+		return nil, ErrInvalidParams
+	}
+
+	tx, err := bc.BuildTransaction(umid.TxDraft{
+		Version:       *prm.Version,
+		Sender:        prm.Sender,
+		Recipient:     prm.Recipient,
+		Value:         prm.Value,
+		Prefix:        prm.Prefix,
+		Name:          prm.Name,
+		ProfitPercent: prm.ProfitPercent,
+		FeePercent:    prm.FeePercent,
+		Address:       prm.Address,
+	})
+	if err != nil {
+		return nil, marshalTxError(err)
+	}
+
+	b, _ := json.Marshal(struct {
+		Tx []byte `json:"base64"`
+	}{
+		Tx: tx,
+	})
+
+	return b, nil
+}
+
+// SignAndSendTx ...
+type SignAndSendTx struct{}
+
+// Name ...
+func (SignAndSendTx) Name() string {
+	return "signAndSendTransaction"
+}
+
+// Process ...
+func (SignAndSendTx) Process(bc umid.IBlockchain, params json.RawMessage) (json.RawMessage, json.RawMessage) {
+	prm := new(struct {
+		Tx []byte `json:"base64"`
+	})
+
+	if err := json.Unmarshal(params, prm); err != nil || prm.Tx == nil {
+		return nil, ErrInvalidParams
+	}
+
+	tx, err := bc.SignTransaction(prm.Tx)
+	if err != nil {
+		if errors.Is(err, umid.ErrKeyNotFound) {
+			return nil, marshalNotFound("key")
+		}
+
+		return nil, marshalTxError(err)
+	}
+
+	if err := bc.AddTransaction(tx); err != nil {
+		return nil, marshalTxError(err)
+	}
+
+	hash := sha256.Sum256(tx)
+
+	b, _ := json.Marshal(struct {
+		Hash string `json:"hash"`
+		Tx   []byte `json:"base64"`
+	}{
+		Hash: hex.EncodeToString(hash[:]),
+		Tx:   tx,
+	})
+
+	return b, nil
+}
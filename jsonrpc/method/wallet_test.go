@@ -0,0 +1,199 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package method_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"umid/jsonrpc"
+	"umid/umid"
+)
+
+func TestPublicRPCHidesWallet(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bc := &bcMock{}
+	bc.FnBuildTransaction = func(umid.TxDraft) ([]byte, error) {
+		t.Error("public rpc reached the wallet")
+
+		return nil, nil
+	}
+	bc.FnSignTransaction = func([]byte) ([]byte, error) {
+		t.Error("public rpc reached the wallet")
+
+		return nil, nil
+	}
+
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"buildTransaction","params":{"version":1},"id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"signAndSendTransaction","params":{"base64":"AA=="},"id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":2}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
+
+func TestBuildTransaction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bc := &bcMock{}
+	bc.FnBuildTransaction = func(d umid.TxDraft) ([]byte, error) {
+		if d.Recipient == "" {
+			return nil, errors.New("invalid recipient")
+		}
+
+		return []byte{d.Version, 2, 3}, nil
+	}
+
+	rpc := jsonrpc.NewWalletRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"buildTransaction","params":{"sender":"umi1a"},"id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"buildTransaction","params":{"version":1},"id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":2}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"buildTransaction","params":{"version":1,"sender":"umi1a"},"id":3}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid recipient"},"id":3}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"buildTransaction","params":{"version":1,"sender":"umi1a","recipient":"umi1b","value":1},"id":4}`,
+			`{"jsonrpc":"2.0","result":{"base64":"AQID"},"id":4}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
+
+func TestSignAndSendTransaction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bc := &bcMock{}
+	bc.FnSignTransaction = func(b []byte) ([]byte, error) {
+		switch b[0] {
+		case 0:
+			return nil, umid.ErrKeyNotFound
+		case 1:
+			return nil, umid.ErrWalletDisabled
+		}
+
+		return append([]byte{}, b...), nil
+	}
+	bc.FnAddTransaction = func(b []byte) error {
+		if bytes.Equal(b, []byte{3}) {
+			return umid.ErrInsufficientFunds
+		}
+
+		return nil
+	}
+
+	rpc := jsonrpc.NewWalletRPC().SetBlockchain(bc)
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"signAndSendTransaction","params":{},"id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"signAndSendTransaction","params":{"base64":"AA=="},"id":2}`,
+			`{"jsonrpc":"2.0","error":{"code":-32004,"message":"key not found"},"id":2}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"signAndSendTransaction","params":{"base64":"AQ=="},"id":3}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"wallet disabled"},"id":3}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"signAndSendTransaction","params":{"base64":"Aw=="},"id":4}`,
+			`{"jsonrpc":"2.0","error":{"code":-32002,"message":"insufficient funds"},"id":4}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"signAndSendTransaction","params":{"base64":"Ag=="},"id":5}`,
+			`{"jsonrpc":"2.0","result":{"hash":"dbc1b4c900ffe48d575b5da5c638040125f65db0fe3e24494b76ea986457d986","base64":"Ag=="},"id":5}`,
+		},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequestWithContext(ctx, "POST", "/json-rpc", strings.NewReader(test.request))
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		handler := http.HandlerFunc(rpc.HTTP)
+		handler.ServeHTTP(res, req)
+
+		if res.Body.String() != test.response {
+			t.Errorf("unexpected body: got %v want %v", res.Body.String(), test.response)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"umid/umid"
+
+	"github.com/umitop/libumi"
+)
+
+const maxSubscriptions = 32
+
+// Subscription topics.
+const (
+	topicNewBlocks           = "newBlocks"
+	topicConfirmedBlocks     = "confirmedBlocks"
+	topicAddressTransactions = "addressTransactions"
+	topicStructureChanges    = "structureChanges"
+	topicReorgs              = "reorgs"
+)
+
+var (
+	errInvalidParams = []byte(`{"code":-32602,"message":"Invalid params"}`)
+	errTooManySubs   = []byte(`{"code":-32005,"message":"too many subscriptions"}`)
+)
+
+type subscription struct {
+	topic   string
+	address []byte
+	prefix  string
+}
+
+type blockEvent struct {
+	Height       uint32   `json:"height"`
+	Hash         string   `json:"hash"`
+	Transactions []string `json:"transactions,omitempty"`
+}
+
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  struct {
+		Subscription uint64      `json:"subscription"`
+		Result       interface{} `json:"result"`
+	} `json:"params"`
+}
+
+func newSubscription(params json.RawMessage) (*subscription, bool) {
+	prm := new(struct {
+		Topic   string `json:"topic"`
+		Address string `json:"address"`
+		Prefix  string `json:"prefix"`
+	})
+
+	if err := json.Unmarshal(params, prm); err != nil {
+		return nil, false
+	}
+
+	sub := &subscription{topic: prm.Topic}
+
+	switch prm.Topic {
+	case topicNewBlocks, topicConfirmedBlocks, topicReorgs:
+		return sub, true
+	case topicAddressTransactions:
+		adr, err := libumi.NewAddressFromBech32(prm.Address)
+		if err != nil {
+			return nil, false
+		}
+
+		sub.address = adr
+
+		return sub, true
+	case topicStructureChanges:
+		if len(prm.Prefix) != 3 {
+			return nil, false
+		}
+
+		sub.prefix = prm.Prefix
+
+		return sub, true
+	}
+
+	return nil, false
+}
+
+// match returns the notification payload for the event or nil if the subscription is not interested in it.
+func (s *subscription) match(e *umid.Event) interface{} {
+	switch {
+	case s.topic == topicNewBlocks && e.Kind == umid.EventNewBlock,
+		s.topic == topicConfirmedBlocks && e.Kind == umid.EventConfirmedBlock,
+		s.topic == topicReorgs && e.Kind == umid.EventReorg:
+		return &blockEvent{Height: e.Height, Hash: hex.EncodeToString(e.Hash)}
+	case e.Kind != umid.EventConfirmedBlock:
+		return nil
+	}
+
+	txs := make([]string, 0)
+
+	for _, tx := range e.Txs {
+		if s.matchTx(tx) {
+			txs = append(txs, hex.EncodeToString(tx.Hash))
+		}
+	}
+
+	if len(txs) == 0 {
+		return nil
+	}
+
+	return &blockEvent{Height: e.Height, Hash: hex.EncodeToString(e.Hash), Transactions: txs}
+}
+
+func (s *subscription) matchTx(tx umid.EventTx) bool {
+	switch s.topic {
+	case topicAddressTransactions:
+		for _, adr := range tx.Addresses {
+			if bytes.Equal(adr, s.address) {
+				return true
+			}
+		}
+	case topicStructureChanges:
+		if tx.Prefix == s.prefix {
+			return true
+		}
+
+		for _, adr := range tx.Addresses {
+			if (libumi.Address)(adr).Prefix() == s.prefix {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func marshalNotification(id uint64, result interface{}) []byte {
+	n := notification{JSONRPC: "2.0", Method: "subscription"}
+	n.Params.Subscription = id
+	n.Params.Result = result
+
+	b, _ := json.Marshal(n)
+
+	return b
+}
+
+// handleSubscription processes subscribe and unsubscribe requests, they are bound to the connection
+// and never reach the shared worker queue.
+func (c *Client) handleSubscription(msg []byte) ([]byte, bool) {
+	req := new(request)
+
+	if err := json.Unmarshal(msg, req); err != nil || req.JSONRPC != "2.0" || req.ID == nil {
+		return nil, false
+	}
+
+	switch req.Method {
+	case "subscribe":
+		res, err := c.subscribe(req.Params)
+
+		return marshalResponse(res, err, req.ID), true
+	case "unsubscribe":
+		res, err := c.unsubscribe(req.Params)
+
+		return marshalResponse(res, err, req.ID), true
+	}
+
+	return nil, false
+}
+
+func (c *Client) subscribe(params json.RawMessage) (result json.RawMessage, error json.RawMessage) {
+	sub, ok := newSubscription(params)
+	if !ok {
+		return nil, errInvalidParams
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.subs) >= maxSubscriptions {
+		return nil, errTooManySubs
+	}
+
+	c.lastSubID++
+	c.subs[c.lastSubID] = sub
+
+	result, _ = json.Marshal(c.lastSubID)
+
+	return result, nil
+}
+
+func (c *Client) unsubscribe(params json.RawMessage) (result json.RawMessage, error json.RawMessage) {
+	prm := new(struct {
+		Subscription uint64 `json:"subscription"`
+	})
+
+	if err := json.Unmarshal(params, prm); err != nil {
+		return nil, errInvalidParams
+	}
+
+	c.mu.Lock()
+	_, ok := c.subs[prm.Subscription]
+	delete(c.subs, prm.Subscription)
+	c.mu.Unlock()
+
+	result, _ = json.Marshal(ok)
+
+	return result, nil
+}
+
+// notifier pushes bus events matching the client subscriptions to the connection.
+func (c *Client) notifier(bus umid.IEventBus) {
+	ch, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case e := <-ch:
+			for _, msg := range c.notifications(e) {
+				select {
+				case c.res <- msg:
+					break
+				case <-c.ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) notifications(e *umid.Event) [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res := make([][]byte, 0)
+
+	for id, sub := range c.subs {
+		if v := sub.match(e); v != nil {
+			res = append(res, marshalNotification(id, v))
+		}
+	}
+
+	return res
+}
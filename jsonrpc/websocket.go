@@ -24,6 +24,7 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -33,11 +34,14 @@ const wsQueueLen = 16
 
 // Client ...
 type Client struct {
-	ctx    context.Context
-	cancel func()
-	conn   *websocket.Conn
-	res    chan []byte
-	req    chan<- rawRequest
+	ctx       context.Context
+	cancel    func()
+	conn      *websocket.Conn
+	res       chan []byte
+	req       chan<- rawRequest
+	mu        sync.Mutex
+	subs      map[uint64]*subscription
+	lastSubID uint64
 }
 
 // NewClient ...
@@ -50,6 +54,7 @@ func NewClient(conn *websocket.Conn, req chan<- rawRequest) *Client {
 		conn:   conn,
 		res:    make(chan []byte, wsQueueLen),
 		req:    req,
+		subs:   make(map[uint64]*subscription),
 	}
 }
 
@@ -63,6 +68,10 @@ func (rpc *RPC) WebSocket(w http.ResponseWriter, r *http.Request) {
 	cl := NewClient(conn, rpc.queue)
 	go cl.reader()
 	go cl.writer()
+
+	if rpc.bus != nil {
+		go cl.notifier(rpc.bus)
+	}
 }
 
 func (c *Client) reader() {
@@ -79,9 +88,22 @@ func (c *Client) reader() {
 			break
 		}
 
-		if msgType == websocket.TextMessage {
-			c.req <- rawRequest{c.ctx, msg, c.res}
+		if msgType != websocket.TextMessage {
+			continue
 		}
+
+		if res, ok := c.handleSubscription(msg); ok {
+			select {
+			case c.res <- res:
+				break
+			case <-c.ctx.Done():
+				break
+			}
+
+			continue
+		}
+
+		c.req <- rawRequest{c.ctx, msg, c.res}
 	}
 }
 
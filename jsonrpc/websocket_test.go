@@ -0,0 +1,155 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package jsonrpc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"umid/events"
+	"umid/jsonrpc"
+	"umid/umid"
+
+	"github.com/gorilla/websocket"
+	"github.com/umitop/libumi"
+)
+
+func TestWebSocketSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := events.NewBus()
+	rpc := jsonrpc.NewRPC().SetEventBus(bus)
+
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	srv := httptest.NewServer(http.HandlerFunc(rpc.WebSocket))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	adr := libumi.NewAddress()
+	adr.SetPublicKey(make([]byte, 32))
+
+	tests := []struct {
+		request  string
+		response string
+	}{
+		{
+			`{"jsonrpc":"2.0","method":"subscribe","params":{"topic":"abc"},"id":1}`,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"subscribe","params":{"topic":"addressTransactions","address":"` + adr.Bech32() + `"},"id":2}`,
+			`{"jsonrpc":"2.0","result":1,"id":2}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"subscribe","params":{"topic":"newBlocks"},"id":3}`,
+			`{"jsonrpc":"2.0","result":2,"id":3}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"unsubscribe","params":{"subscription":2},"id":4}`,
+			`{"jsonrpc":"2.0","result":true,"id":4}`,
+		},
+		{
+			`{"jsonrpc":"2.0","method":"unsubscribe","params":{"subscription":2},"id":5}`,
+			`{"jsonrpc":"2.0","result":false,"id":5}`,
+		},
+	}
+
+	for _, test := range tests {
+		if got := roundTrip(t, conn, test.request); got != test.response {
+			t.Errorf("unexpected body: got %v want %v", got, test.response)
+		}
+	}
+
+	bus.Publish(&umid.Event{Kind: umid.EventNewBlock, Height: 2, Hash: []byte{1}})
+	bus.Publish(&umid.Event{Kind: umid.EventConfirmedBlock, Height: 2, Hash: []byte{1}, Txs: []umid.EventTx{
+		{Hash: []byte{2}, Addresses: [][]byte{make([]byte, 34)}},
+		{Hash: []byte{3}, Addresses: [][]byte{adr}},
+	}})
+
+	exp := `{"jsonrpc":"2.0","method":"subscription","params":{"subscription":1,"result":{"height":2,"hash":"01","transactions":["03"]}}}`
+
+	if got := read(t, conn); got != exp {
+		t.Errorf("unexpected notification: got %v want %v", got, exp)
+	}
+}
+
+func TestWebSocketReorgs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := events.NewBus()
+	rpc := jsonrpc.NewRPC().SetEventBus(bus)
+
+	go rpc.Worker(ctx, &sync.WaitGroup{})
+
+	srv := httptest.NewServer(http.HandlerFunc(rpc.WebSocket))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	req := `{"jsonrpc":"2.0","method":"subscribe","params":{"topic":"reorgs"},"id":1}`
+	if got, exp := roundTrip(t, conn, req), `{"jsonrpc":"2.0","result":1,"id":1}`; got != exp {
+		t.Fatalf("unexpected body: got %v want %v", got, exp)
+	}
+
+	bus.Publish(&umid.Event{Kind: umid.EventNewBlock, Height: 6, Hash: []byte{6}})
+	bus.Publish(&umid.Event{Kind: umid.EventReorg, Height: 5, Hash: []byte{5}})
+
+	exp := `{"jsonrpc":"2.0","method":"subscription","params":{"subscription":1,"result":{"height":5,"hash":"05"}}}`
+
+	if got := read(t, conn); got != exp {
+		t.Errorf("unexpected notification: got %v want %v", got, exp)
+	}
+}
+
+func roundTrip(t *testing.T, conn *websocket.Conn, req string) string {
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(req)); err != nil {
+		t.Fatal(err)
+	}
+
+	return read(t, conn)
+}
+
+func read(t *testing.T, conn *websocket.Conn) string {
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(msg)
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package keystore
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const reloadIntervalSec = 10
+
+// Keystore ...
+type Keystore struct {
+	mu        sync.RWMutex
+	path      string
+	password  []byte
+	raw       []byte
+	secretKey ed25519.PrivateKey
+	validator func([]byte) error
+}
+
+// NewKeystore ...
+func NewKeystore() *Keystore {
+	path := os.Getenv("KEYSTORE")
+	if path == "" {
+		return &Keystore{}
+	}
+
+	ks, err := LoadKeystore(path, []byte(os.Getenv("KEYSTORE_PASSWORD")))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	return ks
+}
+
+// LoadKeystore reads the key from the file, it is checked against the approved keys once a validator is set.
+func LoadKeystore(path string, password []byte) (*Keystore, error) {
+	ks := &Keystore{path: path, password: password}
+
+	return ks, ks.Reload()
+}
+
+// SetValidator checks the loaded key right away and every key picked up by Reload afterwards.
+func (ks *Keystore) SetValidator(fn func([]byte) error) error {
+	ks.mu.Lock()
+	ks.validator = fn
+	sec := ks.secretKey
+	ks.mu.Unlock()
+
+	if sec == nil {
+		return nil
+	}
+
+	return fn(sec.Public().(ed25519.PublicKey))
+}
+
+// Worker ...
+func (ks *Keystore) Worker(ctx context.Context, wg *sync.WaitGroup) {
+	if ks.path == "" {
+		return
+	}
+
+	wg.Add(1)
+	defer wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reloadIntervalSec * time.Second):
+			if err := ks.Reload(); err != nil {
+				log.Println(err.Error())
+			}
+		}
+	}
+}
+
+// SecretKey ...
+func (ks *Keystore) SecretKey() ed25519.PrivateKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	return ks.secretKey
+}
+
+// Reload swaps the key only if the file has changed and the new key is accepted by the validator.
+func (ks *Keystore) Reload() error {
+	b, err := ioutil.ReadFile(ks.path)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(b, ks.raw) {
+		return nil
+	}
+
+	sec, err := ParseSecretKey(b, ks.password)
+	if err != nil {
+		return err
+	}
+
+	pub := sec.Public().(ed25519.PublicKey)
+
+	ks.mu.RLock()
+	validator := ks.validator
+	ks.mu.RUnlock()
+
+	if validator != nil {
+		if err := validator(pub); err != nil {
+			return err
+		}
+	}
+
+	// a rejected file is checked again on the next reload, the key may be approved by then
+	ks.mu.Lock()
+	ks.raw = b
+	ks.secretKey = sec
+	ks.mu.Unlock()
+
+	log.Printf("keystore: loaded public key %s\n", hex.EncodeToString(pub))
+
+	return nil
+}
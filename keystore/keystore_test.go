@@ -0,0 +1,111 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package keystore_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"umid/keystore"
+)
+
+func TestKeystoreRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pwd := []byte("secret")
+	path := filepath.Join(dir, "key.pem")
+
+	keys := make([]ed25519.PrivateKey, 3)
+	for i := range keys {
+		_, keys[i], _ = ed25519.GenerateKey(nil)
+	}
+
+	write := func(sec ed25519.PrivateKey) {
+		b, _ := keystore.EncryptSecretKey(sec, pwd)
+		if err := ioutil.WriteFile(path, b, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the third key is not approved until it is registered
+	registered := false
+	validator := func(pub []byte) error {
+		if !registered && bytes.Equal(pub, keys[2].Public().(ed25519.PublicKey)) {
+			return errors.New("not approved")
+		}
+
+		return nil
+	}
+
+	write(keys[0])
+
+	ks, err := keystore.LoadKeystore(path, pwd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := ks.SetValidator(validator); err != nil {
+		t.Fatalf("approved key rejected: %v", err)
+	}
+
+	write(keys[1])
+
+	if err := ks.Reload(); err != nil || !bytes.Equal(ks.SecretKey(), keys[1]) {
+		t.Fatalf("rotated key was not picked up: %v", err)
+	}
+
+	write(keys[2])
+
+	if err := ks.Reload(); err == nil {
+		t.Error("expected the unapproved key to be rejected")
+	}
+
+	if !bytes.Equal(ks.SecretKey(), keys[1]) {
+		t.Error("rejected key must not replace the current one")
+	}
+
+	// the same file is picked up once the key is approved
+	registered = true
+
+	if err := ks.Reload(); err != nil || !bytes.Equal(ks.SecretKey(), keys[2]) {
+		t.Fatalf("approved key was not picked up: %v", err)
+	}
+
+	registered = false
+
+	// an unapproved key fails at startup
+	ks, err = keystore.LoadKeystore(path, pwd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := ks.SetValidator(validator); err == nil {
+		t.Error("expected the unapproved key to be rejected at startup")
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"strconv"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	pemPlain     = "PRIVATE KEY"
+	pemEncrypted = "UMI ENCRYPTED PRIVATE KEY"
+
+	kdfIterations = 100_000
+	kdfSaltLen    = 16
+	kdfKeyLen     = 32
+)
+
+// Errors.
+var (
+	ErrInvalidPEM      = errors.New("invalid pem")
+	ErrInvalidKey      = errors.New("invalid secret key")
+	ErrInvalidPassword = errors.New("invalid password")
+)
+
+// ParseSecretKey ...
+func ParseSecretKey(b []byte, password []byte) (ed25519.PrivateKey, error) {
+	blk, _ := pem.Decode(b)
+	if blk == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	der := blk.Bytes
+
+	switch blk.Type {
+	case pemPlain:
+		break
+	case pemEncrypted:
+		var err error
+		if der, err = decrypt(blk, password); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidPEM
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	sec, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	return sec, nil
+}
+
+// EncryptSecretKey ...
+func EncryptSecretKey(sec ed25519.PrivateKey, password []byte) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(sec)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, kdfSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(password, salt, kdfIterations)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	blk := &pem.Block{
+		Type: pemEncrypted,
+		Headers: map[string]string{
+			"Iterations": strconv.Itoa(kdfIterations),
+			"Salt":       hex.EncodeToString(salt),
+			"Nonce":      hex.EncodeToString(nonce),
+		},
+		Bytes: aead.Seal(nil, nonce, der, nil),
+	}
+
+	return pem.EncodeToMemory(blk), nil
+}
+
+func decrypt(blk *pem.Block, password []byte) ([]byte, error) {
+	iter, err := strconv.Atoi(blk.Headers["Iterations"])
+	if err != nil || iter < 1 {
+		return nil, ErrInvalidPEM
+	}
+
+	salt, err := hex.DecodeString(blk.Headers["Salt"])
+	if err != nil {
+		return nil, ErrInvalidPEM
+	}
+
+	nonce, err := hex.DecodeString(blk.Headers["Nonce"])
+	if err != nil {
+		return nil, ErrInvalidPEM
+	}
+
+	aead, err := newAEAD(password, salt, iter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrInvalidPEM
+	}
+
+	der, err := aead.Open(nil, nonce, blk.Bytes, nil)
+	if err != nil {
+		return nil, ErrInvalidPassword
+	}
+
+	return der, nil
+}
+
+func newAEAD(password []byte, salt []byte, iter int) (cipher.AEAD, error) {
+	key := pbkdf2.Key(password, salt, iter, kdfKeyLen, sha256.New)
+
+	blk, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(blk)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package keystore_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"umid/keystore"
+)
+
+func TestParseSecretKeyPlain(t *testing.T) {
+	_, sec, _ := ed25519.GenerateKey(nil)
+
+	der, _ := x509.MarshalPKCS8PrivateKey(sec)
+	b := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	key, err := keystore.ParseSecretKey(b, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(key, sec) {
+		t.Errorf("wrong secret key: got %x want %x", key, sec)
+	}
+}
+
+func TestParseSecretKeyEncrypted(t *testing.T) {
+	_, sec, _ := ed25519.GenerateKey(nil)
+	pwd := []byte("secret")
+
+	b, err := keystore.EncryptSecretKey(sec, pwd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key, err := keystore.ParseSecretKey(b, pwd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(key, sec) {
+		t.Errorf("wrong secret key: got %x want %x", key, sec)
+	}
+
+	if _, err := keystore.ParseSecretKey(b, []byte("wrong")); !errors.Is(err, keystore.ErrInvalidPassword) {
+		t.Errorf("wrong error: got %v want %v", err, keystore.ErrInvalidPassword)
+	}
+}
+
+func TestParseSecretKeyInvalid(t *testing.T) {
+	tests := []struct {
+		data []byte
+		err  error
+	}{
+		{[]byte("not a pem"), keystore.ErrInvalidPEM},
+		{pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte{1}}), keystore.ErrInvalidPEM},
+		{pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1}}), keystore.ErrInvalidKey},
+		{pem.EncodeToMemory(&pem.Block{Type: "UMI ENCRYPTED PRIVATE KEY", Bytes: []byte{1}}), keystore.ErrInvalidPEM},
+	}
+
+	for _, test := range tests {
+		if _, err := keystore.ParseSecretKey(test.data, nil); !errors.Is(err, test.err) {
+			t.Errorf("wrong error: got %v want %v", err, test.err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package keystore
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrWalletPassword ...
+var ErrWalletPassword = errors.New("wallet password is not set")
+
+// Wallet holds secret keys used to sign transactions on behalf of local clients. It is loaded from a directory of
+// encrypted PEM files and stays disabled unless WALLET_DIR is set.
+type Wallet struct {
+	mu   sync.RWMutex
+	keys map[string]ed25519.PrivateKey
+}
+
+// NewWallet ...
+func NewWallet() *Wallet {
+	dir, ok := os.LookupEnv("WALLET_DIR")
+	if !ok || dir == "" {
+		return &Wallet{}
+	}
+
+	w, err := LoadWallet(dir, []byte(os.Getenv("WALLET_PASSWORD")))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	log.Printf("wallet: loaded %d keys\n", len(w.keys))
+
+	return w
+}
+
+// LoadWallet reads every *.pem file in the directory, unencrypted keys are rejected.
+func LoadWallet(dir string, password []byte) (*Wallet, error) {
+	if len(password) == 0 {
+		return nil, ErrWalletPassword
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Wallet{keys: make(map[string]ed25519.PrivateKey, len(files))}
+
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		if blk, _ := pem.Decode(b); blk == nil || blk.Type != pemEncrypted {
+			return nil, fmt.Errorf("wallet: %s: %w", filepath.Base(f), ErrInvalidPEM)
+		}
+
+		sec, err := ParseSecretKey(b, password)
+		if err != nil {
+			return nil, fmt.Errorf("wallet: %s: %w", filepath.Base(f), err)
+		}
+
+		w.keys[hex.EncodeToString(sec.Public().(ed25519.PublicKey))] = sec
+	}
+
+	return w, nil
+}
+
+// Enabled ...
+func (w *Wallet) Enabled() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.keys != nil
+}
+
+// SecretKey returns the key matching the public key, if the wallet has one.
+func (w *Wallet) SecretKey(pub []byte) (ed25519.PrivateKey, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	sec, ok := w.keys[hex.EncodeToString(pub)]
+
+	return sec, ok
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package keystore_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"umid/keystore"
+)
+
+func TestLoadWallet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pwd := []byte("secret")
+	pub, sec, _ := ed25519.GenerateKey(nil)
+
+	b, _ := keystore.EncryptSecretKey(sec, pwd)
+	_ = ioutil.WriteFile(filepath.Join(dir, "a.pem"), b, 0600)
+	_ = ioutil.WriteFile(filepath.Join(dir, "readme.txt"), []byte("ignored"), 0600)
+
+	w, err := keystore.LoadWallet(dir, pwd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !w.Enabled() {
+		t.Error("wallet should be enabled")
+	}
+
+	key, ok := w.SecretKey(pub)
+	if !ok || !bytes.Equal(key, sec) {
+		t.Errorf("wrong secret key: got %x want %x", key, sec)
+	}
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	if _, ok := w.SecretKey(other); ok {
+		t.Error("unknown key should not be found")
+	}
+
+	if _, err := keystore.LoadWallet(dir, []byte("wrong")); !errors.Is(err, keystore.ErrInvalidPassword) {
+		t.Errorf("expected invalid password, got %v", err)
+	}
+
+	if _, err := keystore.LoadWallet(dir, nil); !errors.Is(err, keystore.ErrWalletPassword) {
+		t.Errorf("expected missing password, got %v", err)
+	}
+}
+
+func TestLoadWalletPlainKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, sec, _ := ed25519.GenerateKey(nil)
+	der, _ := x509.MarshalPKCS8PrivateKey(sec)
+	_ = ioutil.WriteFile(filepath.Join(dir, "a.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+
+	if _, err := keystore.LoadWallet(dir, []byte("secret")); !errors.Is(err, keystore.ErrInvalidPEM) {
+		t.Errorf("expected invalid pem, got %v", err)
+	}
+}
+
+func TestWalletDisabled(t *testing.T) {
+	if (&keystore.Wallet{}).Enabled() {
+		t.Error("zero wallet should be disabled")
+	}
+}
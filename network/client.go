@@ -26,6 +26,7 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -64,17 +65,38 @@ func newClient() *http.Client {
 	return client
 }
 
-func peer() (url string) {
-	switch os.Getenv("NETWORK") {
-	case "testnet":
-		url = "https://testnet.umi.top"
-	default:
-		url = "https://mainnet.umi.top"
+// defaultPeer returns the public node of the configured network.
+func defaultPeer() string {
+	if os.Getenv("NETWORK") == "testnet" {
+		return "https://testnet.umi.top"
 	}
 
-	if val, ok := os.LookupEnv("PEER"); ok {
-		url = val
+	return "https://mainnet.umi.top"
+}
+
+// configuredPeers returns peers from the comma-separated PEERS list and PEER, or the default one if neither is set.
+func configuredPeers() []string {
+	urls := make([]string, 0)
+
+	if val, ok := os.LookupEnv("PEERS"); ok {
+		for _, u := range strings.Split(val, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				urls = append(urls, u)
+			}
+		}
+	}
+
+	if val, ok := os.LookupEnv("PEER"); ok && val != "" {
+		urls = append(urls, val)
 	}
 
-	return fmt.Sprintf("%s/json-rpc", url)
+	if len(urls) == 0 {
+		urls = append(urls, defaultPeer())
+	}
+
+	return urls
+}
+
+func rpcURL(base string) string {
+	return fmt.Sprintf("%s/json-rpc", strings.TrimSuffix(base, "/"))
 }
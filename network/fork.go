@@ -0,0 +1,165 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	errNoFork        = errors.New("peer has the same block at our height")
+	errNoCommonBlock = errors.New("peer has no blocks in common with us")
+)
+
+// resolveFork is called when the peer sends a block whose parent we do not have. It finds the last block we share
+// with the peer and switches to the peer's branch if that one is longer.
+func (net *Network) resolveFork(ctx context.Context, url string) error {
+	last, err := net.blockchain.LastBlockHeight()
+	if err != nil {
+		return err
+	}
+
+	fork, err := net.forkPoint(ctx, url, last)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("fork at block %d detected with %s\n", fork, url)
+
+	blocks, err := fetchBlocks(ctx, net.client, url, fork+1)
+	if err != nil {
+		return err
+	}
+
+	return net.blockchain.Reorganize(fork, blocks, url)
+}
+
+// forkPoint returns the height of the last block that has the same hash locally and on the peer. Heights up to
+// the fork point match and all heights above it do not, so it is found by binary search.
+func (net *Network) forkPoint(ctx context.Context, url string, last uint32) (uint32, error) {
+	same, err := net.sameBlock(ctx, url, last)
+	if err != nil {
+		return 0, err
+	}
+
+	if same {
+		return 0, errNoFork
+	}
+
+	lo, hi := uint32(0), last
+
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+
+		same, err := net.sameBlock(ctx, url, mid)
+		if err != nil {
+			return 0, err
+		}
+
+		if same {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	if lo == 0 {
+		return 0, errNoCommonBlock
+	}
+
+	return lo, nil
+}
+
+func (net *Network) sameBlock(ctx context.Context, url string, height uint32) (bool, error) {
+	blk, err := net.blockchain.BlockByHeight(height)
+	if err != nil {
+		return false, err
+	}
+
+	hash, err := fetchBlockHash(ctx, net.client, url, height)
+	if err != nil {
+		return false, err
+	}
+
+	return hash == blk.Hash, nil
+}
+
+// fetchBlockHash returns the hash of the peer's block at the height or an empty string if the peer has none.
+func fetchBlockHash(ctx context.Context, client *http.Client, url string, height uint32) (string, error) {
+	const tpl = `{"jsonrpc":"2.0","method":"getBlockHeader","params":{"height":%d},"id":1}`
+
+	body, err := call(ctx, client, url, fmt.Sprintf(tpl, height))
+	if err != nil {
+		return "", err
+	}
+
+	res := new(struct {
+		Result struct {
+			Hash string `json:"hash"`
+		} `json:"result"`
+	})
+
+	if err := json.Unmarshal(body, res); err != nil {
+		return "", err
+	}
+
+	return res.Result.Hash, nil
+}
+
+func fetchBlocks(ctx context.Context, client *http.Client, url string, height uint32) ([][]byte, error) {
+	const tpl = `{"jsonrpc":"2.0","method":"listBlocks","params":{"height":%d},"id":"%d"}`
+
+	body, err := call(ctx, client, url, fmt.Sprintf(tpl, height, time.Now().UnixNano()))
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(struct {
+		Result [][]byte `json:"result"`
+	})
+
+	if err := json.Unmarshal(body, res); err != nil {
+		return nil, err
+	}
+
+	return res.Result, nil
+}
+
+func call(ctx context.Context, client *http.Client, url, jsn string) ([]byte, error) {
+	req, _ := http.NewRequestWithContext(ctx, "POST", rpcURL(url), strings.NewReader(jsn))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	return ioutil.ReadAll(resp.Body)
+}
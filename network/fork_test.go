@@ -0,0 +1,81 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"umid/umid"
+)
+
+type chainMock struct {
+	umid.IBlockchain
+	hashes []string
+}
+
+func (m *chainMock) BlockByHeight(n uint32) (*umid.Block, error) {
+	return &umid.Block{Height: n, Hash: m.hashes[n-1]}, nil
+}
+
+func TestForkPoint(t *testing.T) {
+	local := []string{"01", "02", "03", "04", "05", "06", "07"}
+	remote := []string{"01", "02", "03", "04", "15", "16", "17", "18"}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		req := new(struct {
+			Params struct {
+				Height uint32 `json:"height"`
+			} `json:"params"`
+		})
+		_ = json.Unmarshal(body, req)
+
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","result":{"hash":"%s"},"id":1}`, remote[req.Params.Height-1])
+	}))
+	defer srv.Close()
+
+	net := NewNetwork().SetBlockchain(&chainMock{hashes: local})
+
+	fork, err := net.forkPoint(context.Background(), srv.URL, uint32(len(local)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fork != 4 {
+		t.Errorf("wrong fork point: got %d want 4", fork)
+	}
+
+	if _, err := net.forkPoint(context.Background(), srv.URL, 4); err != errNoFork {
+		t.Errorf("expected %v, got %v", errNoFork, err)
+	}
+
+	copy(remote, []string{"11", "12", "13", "14"})
+
+	if _, err := net.forkPoint(context.Background(), srv.URL, uint32(len(local))); err != errNoCommonBlock {
+		t.Errorf("expected %v, got %v", errNoCommonBlock, err)
+	}
+}
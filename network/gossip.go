@@ -0,0 +1,184 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gossipIntervalSec = 60
+	gossipFanout      = 3
+)
+
+// gossiper restores peers discovered in previous runs, then periodically asks the best peers for theirs and
+// persists the state of all known peers.
+func (net *Network) gossiper(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	defer wg.Done()
+
+	if peers, err := net.blockchain.Peers(maxPeers); err != nil {
+		log.Println(err.Error())
+	} else {
+		net.peers.load(peers)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(gossipIntervalSec * time.Second):
+			net.gossip(ctx)
+		}
+	}
+}
+
+func (net *Network) gossip(ctx context.Context) {
+	urls := net.peers.candidates()
+	if len(urls) > gossipFanout {
+		urls = urls[:gossipFanout]
+	}
+
+	for _, u := range urls {
+		lst, err := fetchPeers(ctx, net.client, u)
+		if err != nil {
+			net.peers.failure(u)
+
+			continue
+		}
+
+		for _, p := range lst {
+			if p, ok := normalizePeerURL(p); ok && net.peers.add(p) {
+				log.Printf("peer %s discovered via %s\n", p, u)
+			}
+		}
+	}
+
+	if err := net.blockchain.SavePeers(net.peers.snapshot()); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+func fetchPeers(ctx context.Context, client *http.Client, u string) ([]string, error) {
+	const jsn = `{"jsonrpc":"2.0","method":"getPeers","id":1}`
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", rpcURL(u), strings.NewReader(jsn))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(struct {
+		Result []struct {
+			URL string `json:"url"`
+		} `json:"result"`
+	})
+
+	if err := json.Unmarshal(body, res); err != nil {
+		return nil, err
+	}
+
+	lst := make([]string, len(res.Result))
+	for i, p := range res.Result {
+		lst[i] = p.URL
+	}
+
+	return lst, nil
+}
+
+// privateNets are the address ranges that are not reachable from the internet, in addition to loopback,
+// link-local and unspecified addresses.
+var privateNets = parseCIDRs("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7")
+
+// normalizePeerURL accepts only absolute http(s) URLs of public hosts without query and strips a trailing slash.
+// Peers on private or loopback addresses can only be configured explicitly, never learned via gossip.
+func normalizePeerURL(s string) (string, bool) {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", false
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+
+	if !isPublicHost(u.Hostname()) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(u.String(), "/"), true
+}
+
+func isPublicHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return true
+	}
+
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return false
+	}
+
+	for _, n := range privateNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseCIDRs(lst ...string) []*net.IPNet {
+	res := make([]*net.IPNet, len(lst))
+
+	for i, s := range lst {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			panic(err)
+		}
+
+		res[i] = n
+	}
+
+	return res
+}
@@ -32,12 +32,14 @@ import (
 type Network struct {
 	blockchain umid.IBlockchain
 	client     *http.Client
+	peers      *peerManager
 }
 
 // NewNetwork ...
 func NewNetwork() *Network {
 	return &Network{
 		client: newClient(),
+		peers:  newPeerManager(configuredPeers()),
 	}
 }
 
@@ -54,6 +56,8 @@ func (net *Network) Worker(ctx context.Context, wg *sync.WaitGroup) {
 		return
 	}
 
+	go net.gossiper(ctx, wg)
+	go net.prober(ctx, wg)
 	go net.puller(ctx, wg)
 	go net.pusher(ctx, wg)
 }
@@ -0,0 +1,290 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package network
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+	"umid/umid"
+)
+
+const (
+	peerBanDuration = time.Hour
+	pushFanout      = 3
+	maxPeers        = 64
+	// maxPeerFailures is the number of failures in a row after which a discovered peer may be evicted.
+	maxPeerFailures = 5
+	// maxHeightLead is how far above the median a reported height is believed, higher claims are ignored.
+	maxHeightLead = 1000
+)
+
+type peer struct {
+	url         string
+	height      uint32
+	latency     time.Duration
+	failures    int
+	lastSeen    time.Time
+	bannedUntil time.Time
+	configured  bool
+}
+
+// peerManager keeps the state of known peers and orders them for pulling and pushing. Healthy peers come first,
+// then the ones with the highest believable reported height, then the fastest.
+type peerManager struct {
+	mu    sync.Mutex
+	peers map[string]*peer
+}
+
+func newPeerManager(urls []string) *peerManager {
+	pm := &peerManager{peers: make(map[string]*peer, len(urls))}
+
+	for _, u := range urls {
+		pm.peers[u] = &peer{url: u, configured: true}
+	}
+
+	return pm
+}
+
+// add adds a discovered peer, making room by evicting a banned or failing one when the list is full.
+func (pm *peerManager) add(url string) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if _, ok := pm.peers[url]; ok {
+		return false
+	}
+
+	if len(pm.peers) >= maxPeers && !pm.evict() {
+		return false
+	}
+
+	pm.peers[url] = &peer{url: url}
+
+	return true
+}
+
+// evict removes the discovered peer with the most failures among the banned and failing ones.
+// Configured peers are never evicted.
+func (pm *peerManager) evict() bool {
+	now := time.Now()
+
+	var worst *peer
+
+	for _, p := range pm.peers {
+		if p.configured || (now.After(p.bannedUntil) && p.failures < maxPeerFailures) {
+			continue
+		}
+
+		if worst == nil || p.failures > worst.failures || (p.failures == worst.failures && p.url < worst.url) {
+			worst = p
+		}
+	}
+
+	if worst == nil {
+		return false
+	}
+
+	delete(pm.peers, worst.url)
+
+	log.Printf("peer %s evicted\n", worst.url)
+
+	return true
+}
+
+// load adds persisted peers together with their state. Private and loopback addresses are only kept when they
+// are configured explicitly.
+func (pm *peerManager) load(peers []*umid.Peer) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	for _, p := range peers {
+		if _, ok := pm.peers[p.URL]; ok || len(pm.peers) >= maxPeers {
+			continue
+		}
+
+		if _, ok := normalizePeerURL(p.URL); !ok {
+			continue
+		}
+
+		np := &peer{url: p.URL, failures: p.Failures}
+
+		if p.LastSeen > 0 {
+			np.lastSeen = time.Unix(p.LastSeen, 0)
+		}
+
+		if p.BannedUntil > 0 {
+			np.bannedUntil = time.Unix(p.BannedUntil, 0)
+		}
+
+		pm.peers[p.URL] = np
+	}
+}
+
+// snapshot returns the state of all peers for persisting.
+func (pm *peerManager) snapshot() []*umid.Peer {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	res := make([]*umid.Peer, 0, len(pm.peers))
+
+	for _, p := range pm.peers {
+		s := &umid.Peer{URL: p.url, Failures: p.failures}
+
+		if !p.lastSeen.IsZero() {
+			s.LastSeen = p.lastSeen.Unix()
+		}
+
+		if !p.bannedUntil.IsZero() {
+			s.BannedUntil = p.bannedUntil.Unix()
+		}
+
+		res = append(res, s)
+	}
+
+	return res
+}
+
+// candidates returns urls of peers that are not banned, best first.
+func (pm *peerManager) candidates() []string {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	now := time.Now()
+	lst := make([]*peer, 0, len(pm.peers))
+
+	for _, p := range pm.peers {
+		if now.After(p.bannedUntil) {
+			lst = append(lst, p)
+		}
+	}
+
+	limit := medianHeight(lst) + maxHeightLead
+	height := func(p *peer) uint32 {
+		if p.height > limit {
+			return 0
+		}
+
+		return p.height
+	}
+
+	sort.Slice(lst, func(i, j int) bool {
+		a, b := lst[i], lst[j]
+
+		switch {
+		case a.failures != b.failures:
+			return a.failures < b.failures
+		case height(a) != height(b):
+			return height(a) > height(b)
+		case a.latency != b.latency:
+			return a.latency < b.latency
+		}
+
+		return a.url < b.url
+	})
+
+	res := make([]string, len(lst))
+	for i, p := range lst {
+		res[i] = p.url
+	}
+
+	return res
+}
+
+// medianHeight returns the lower median of the reported heights, so that a single peer can not raise it.
+func medianHeight(lst []*peer) uint32 {
+	heights := make([]uint32, 0, len(lst))
+
+	for _, p := range lst {
+		if p.height > 0 {
+			heights = append(heights, p.height)
+		}
+	}
+
+	if len(heights) == 0 {
+		return 0
+	}
+
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+
+	return heights[(len(heights)-1)/2]
+}
+
+func (pm *peerManager) success(url string, latency time.Duration) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	p, ok := pm.peers[url]
+	if !ok {
+		return
+	}
+
+	p.failures = 0
+	p.lastSeen = time.Now()
+
+	if p.latency == 0 {
+		p.latency = latency
+	} else {
+		p.latency = (p.latency*7 + latency) / 8
+	}
+}
+
+func (pm *peerManager) setHeight(url string, height uint32) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if p, ok := pm.peers[url]; ok {
+		p.height = height
+	}
+}
+
+// reportedHeight returns the last height the peer claimed to have.
+func (pm *peerManager) reportedHeight(url string) uint32 {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if p, ok := pm.peers[url]; ok {
+		return p.height
+	}
+
+	return 0
+}
+
+func (pm *peerManager) failure(url string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if p, ok := pm.peers[url]; ok {
+		p.failures++
+	}
+}
+
+func (pm *peerManager) ban(url string, err error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if p, ok := pm.peers[url]; ok {
+		p.bannedUntil = time.Now().Add(peerBanDuration)
+
+		log.Printf("peer %s banned: %s\n", url, err.Error())
+	}
+}
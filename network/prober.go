@@ -0,0 +1,109 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const probeIntervalSec = 30
+
+func (net *Network) prober(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	defer wg.Done()
+
+	net.probe(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(probeIntervalSec * time.Second):
+			net.probe(ctx)
+		}
+	}
+}
+
+// probe refreshes the height and latency of every usable peer.
+func (net *Network) probe(ctx context.Context) {
+	wg := &sync.WaitGroup{}
+
+	for _, url := range net.peers.candidates() {
+		wg.Add(1)
+
+		go func(url string) {
+			defer wg.Done()
+
+			height, latency, err := probePeer(ctx, net.client, url)
+			if err != nil {
+				net.peers.failure(url)
+
+				return
+			}
+
+			net.peers.success(url, latency)
+			net.peers.setHeight(url, height)
+		}(url)
+	}
+
+	wg.Wait()
+}
+
+func probePeer(ctx context.Context, client *http.Client, url string) (uint32, time.Duration, error) {
+	const jsn = `{"jsonrpc":"2.0","method":"getLastBlock","id":1}`
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", rpcURL(url), strings.NewReader(jsn))
+
+	start := time.Now()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	latency := time.Since(start)
+
+	// nodes without getLastBlock answer with an error object, their height stays zero
+	res := new(struct {
+		Result struct {
+			Height uint32 `json:"height"`
+		} `json:"result"`
+	})
+
+	if err := json.Unmarshal(body, res); err != nil {
+		return 0, 0, err
+	}
+
+	return res.Result.Height, latency, nil
+}
@@ -23,6 +23,7 @@ package network
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -34,6 +35,8 @@ import (
 
 const pullIntervalSec = 5
 
+var errNothingServed = errors.New("peer reported a higher block but served none")
+
 func (net *Network) puller(ctx context.Context, wg *sync.WaitGroup) {
 	wg.Add(1)
 	defer wg.Done()
@@ -43,53 +46,95 @@ func (net *Network) puller(ctx context.Context, wg *sync.WaitGroup) {
 		case <-ctx.Done():
 			return
 		case <-time.After(pullIntervalSec * time.Second):
-			pull(ctx, net.client, net.blockchain)
+			net.pull(ctx)
 		}
 	}
 }
 
-func pull(ctx context.Context, client *http.Client, bc umid.IBlockchain) {
-	lstBlkHeight, err := bc.LastBlockHeight()
-	if err != nil {
-		return
+// pull asks peers in order of their score until one of them syncs us without errors. Peers that serve invalid
+// blocks are banned, the others are only moved down the list.
+func (net *Network) pull(ctx context.Context) {
+	for _, url := range net.peers.candidates() {
+		err := net.pullFrom(ctx, url)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		var vErr *umid.ValidationError
+		if errors.As(err, &vErr) {
+			net.peers.ban(url, err)
+
+			continue
+		}
+
+		net.peers.failure(url)
 	}
+}
 
+func (net *Network) pullFrom(ctx context.Context, url string) error {
 	const tpl = `{"jsonrpc":"2.0","method":"listBlocks","params":{"height":%d},"id":"%d"}`
-	jsn := fmt.Sprintf(tpl, lstBlkHeight+1, time.Now().UnixNano())
 
-	req, _ := http.NewRequestWithContext(ctx, "POST", peer(), strings.NewReader(jsn))
+	for {
+		lstBlkHeight, err := net.blockchain.LastBlockHeight()
+		if err != nil {
+			return nil
+		}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return
-	}
+		jsn := fmt.Sprintf(tpl, lstBlkHeight+1, time.Now().UnixNano())
 
-	body, err := ioutil.ReadAll(resp.Body)
-	_ = resp.Body.Close()
+		req, _ := http.NewRequestWithContext(ctx, "POST", rpcURL(url), strings.NewReader(jsn))
 
-	if err != nil {
-		return
-	}
+		start := time.Now()
 
-	if processResponse(body, bc) > 0 {
-		pull(ctx, client, bc)
+		resp, err := net.client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		if err != nil {
+			return err
+		}
+
+		net.peers.success(url, time.Since(start))
+
+		cnt, err := processResponse(body, net.blockchain)
+		if errors.Is(err, umid.ErrUnknownParent) {
+			if err = net.resolveFork(ctx, url); err == nil {
+				continue
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if cnt == 0 {
+			if net.peers.reportedHeight(url) > lstBlkHeight {
+				return errNothingServed
+			}
+
+			return nil
+		}
 	}
 }
 
-func processResponse(body []byte, bc umid.IBlockchain) (cnt int) {
+func processResponse(body []byte, bc umid.IBlockchain) (int, error) {
 	res := new(struct {
 		Result [][]byte `json:"result"`
 	})
 
 	if err := json.Unmarshal(body, res); err != nil {
-		return
+		return 0, err
 	}
 
 	for _, b := range res.Result {
 		if err := bc.AddBlock(b); err != nil {
-			return
+			return 0, err
 		}
 	}
 
-	return len(res.Result)
+	return len(res.Result), nil
 }
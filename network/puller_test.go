@@ -0,0 +1,137 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"umid/umid"
+)
+
+var errAddBlock = errors.New("block not added")
+
+type pullMock struct {
+	umid.IBlockchain
+	height uint32
+	reject []byte
+}
+
+func (m *pullMock) LastBlockHeight() (uint32, error) {
+	return m.height, nil
+}
+
+func (m *pullMock) AddBlock(b []byte) error {
+	if bytes.Equal(b, m.reject) {
+		return errAddBlock
+	}
+
+	m.height++
+
+	return nil
+}
+
+// blockServer serves listBlocks with the given blocks for height 1 and nothing above it.
+func blockServer(blocks ...[]byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		req := new(struct {
+			Params struct {
+				Height uint32 `json:"height"`
+			} `json:"params"`
+		})
+		_ = json.Unmarshal(body, req)
+
+		res := [][]byte{}
+		if req.Params.Height == 1 {
+			res = blocks
+		}
+
+		b, _ := json.Marshal(res)
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","result":%s,"id":1}`, b)
+	}))
+}
+
+func TestPullFailsOverOnFailedBlock(t *testing.T) {
+	bad := blockServer([]byte{1})
+	defer bad.Close()
+
+	good := blockServer([]byte{2})
+	defer good.Close()
+
+	bc := &pullMock{reject: []byte{1}}
+	net := &Network{blockchain: bc, client: newClient(), peers: newPeerManager([]string{bad.URL, good.URL})}
+
+	// the good peer goes second
+	net.peers.failure(good.URL)
+
+	net.pull(context.Background())
+
+	if bc.height != 1 {
+		t.Errorf("block from the second peer was not added: height %d", bc.height)
+	}
+
+	if f := net.peers.peers[bad.URL].failures; f != 1 {
+		t.Errorf("failed block should count as a failure: got %d", f)
+	}
+
+	if f := net.peers.peers[good.URL].failures; f != 0 {
+		t.Errorf("good peer should be healthy: got %d failures", f)
+	}
+}
+
+func TestPullFailsOverOnEmptyResponse(t *testing.T) {
+	liar := blockServer()
+	defer liar.Close()
+
+	good := blockServer([]byte{2})
+	defer good.Close()
+
+	bc := &pullMock{}
+	net := &Network{blockchain: bc, client: newClient(), peers: newPeerManager([]string{liar.URL, good.URL})}
+
+	net.peers.setHeight(liar.URL, 500)
+	net.peers.setHeight(good.URL, 1)
+
+	net.pull(context.Background())
+
+	if bc.height != 1 {
+		t.Errorf("block from the second peer was not added: height %d", bc.height)
+	}
+
+	if f := net.peers.peers[liar.URL].failures; f != 1 {
+		t.Errorf("empty response above our height should count as a failure: got %d", f)
+	}
+
+	// a peer that has nothing new is fine
+	net.peers.setHeight(liar.URL, 1)
+
+	if err := net.pullFrom(context.Background(), liar.URL); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
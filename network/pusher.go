@@ -48,13 +48,14 @@ func (net *Network) pusher(ctx context.Context, wg *sync.WaitGroup) {
 		case <-ctx.Done():
 			return
 		case <-time.After(pushIntervalSec * time.Second):
-			push(ctx, net.client, net.blockchain)
+			net.push(ctx)
 		}
 	}
 }
 
-func push(ctx context.Context, client *http.Client, bc umid.IBlockchain) {
-	txs := prepareRequest(bc)
+// push sends the mempool to several of the best peers at once, so a single slow peer does not delay propagation.
+func (net *Network) push(ctx context.Context) {
+	txs := prepareRequest(net.blockchain)
 	if len(txs) == 0 {
 		return
 	}
@@ -66,16 +67,40 @@ func push(ctx context.Context, client *http.Client, bc umid.IBlockchain) {
 	_, _ = gz.Write(jsn)
 	_ = gz.Close()
 
-	req, _ := http.NewRequestWithContext(ctx, "POST", peer(), buf)
+	urls := net.peers.candidates()
+	if len(urls) > pushFanout {
+		urls = urls[:pushFanout]
+	}
+
+	wg := &sync.WaitGroup{}
+
+	for _, url := range urls {
+		wg.Add(1)
+
+		go func(url string) {
+			defer wg.Done()
+
+			if err := pushTo(ctx, net.client, url, buf.Bytes()); err != nil {
+				net.peers.failure(url)
+			}
+		}(url)
+	}
+
+	wg.Wait()
+}
+
+func pushTo(ctx context.Context, client *http.Client, url string, body []byte) error {
+	req, _ := http.NewRequestWithContext(ctx, "POST", rpcURL(url), bytes.NewReader(body))
 	req.Header.Set("Content-Encoding", "gzip")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return
+		return err
 	}
 
 	_, _ = io.Copy(ioutil.Discard, resp.Body)
-	_ = resp.Body.Close()
+
+	return resp.Body.Close()
 }
 
 func prepareRequest(bc umid.IBlockchain) []json.RawMessage {
@@ -24,8 +24,10 @@ import (
 	"context"
 	"errors"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -35,10 +37,13 @@ const (
 	drainingTimeoutSec  = 1
 )
 
+var errWalletListen = errors.New("WALLET_LISTEN must be a loopback address or a unix socket path")
+
 // Server ...
 type Server struct {
 	Readiness bool
 	http      *http.Server
+	unix      bool
 }
 
 // NewServer ...
@@ -64,13 +69,88 @@ func NewServer() *Server {
 	return srv
 }
 
+// NewWalletServer serves the handler on WALLET_LISTEN, a loopback host:port or an absolute unix socket path.
+// It has its own mux, so none of the public handlers are reachable through it.
+func NewWalletServer(h http.Handler) (*Server, error) {
+	addr := "127.0.0.1:8081"
+	if val, ok := os.LookupEnv("WALLET_LISTEN"); ok {
+		addr = val
+	}
+
+	unix := strings.HasPrefix(addr, "/")
+	if !unix && !isLoopback(addr) {
+		return nil, errWalletListen
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/json-rpc", h)
+
+	srv := &Server{
+		Readiness: true,
+		http: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadTimeout:       time.Second,
+			WriteTimeout:      time.Second * httpWriteTimeoutSec,
+			IdleTimeout:       time.Second * httpIdleTimeoutSec,
+			ReadHeaderTimeout: time.Second,
+		},
+		unix: unix,
+	}
+
+	return srv, nil
+}
+
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
 // Serve ...
 func (s *Server) Serve() {
-	if err := s.http.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	var err error
+
+	if s.unix {
+		err = s.serveUnix()
+	} else {
+		err = s.http.ListenAndServe()
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Println(err.Error())
 	}
 }
 
+// serveUnix listens on a socket only the node user can connect to, a stale socket is removed first.
+func (s *Server) serveUnix() error {
+	if err := os.Remove(s.http.Addr); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	ln, err := net.Listen("unix", s.http.Addr)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Chmod(s.http.Addr, 0600); err != nil {
+		_ = ln.Close()
+
+		return err
+	}
+
+	return s.http.Serve(ln)
+}
+
 // Shutdown ...
 func (s *Server) Shutdown() {
 	log.Println("shut down")
@@ -0,0 +1,59 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package network
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestNewWalletServer(t *testing.T) {
+	defer os.Unsetenv("WALLET_LISTEN")
+
+	tests := []struct {
+		addr string
+		err  error
+	}{
+		{"127.0.0.1:8081", nil},
+		{"localhost:8081", nil},
+		{"[::1]:8081", nil},
+		{"/run/umid/wallet.sock", nil},
+		{"0.0.0.0:8081", errWalletListen},
+		{":8081", errWalletListen},
+		{"10.0.0.1:8081", errWalletListen},
+		{"wallet.sock", errWalletListen},
+	}
+
+	for _, test := range tests {
+		_ = os.Setenv("WALLET_LISTEN", test.addr)
+
+		srv, err := NewWalletServer(http.NotFoundHandler())
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: got %v want %v", test.addr, err, test.err)
+		}
+
+		if err == nil && srv.unix != (test.addr[0] == '/') {
+			t.Errorf("%s: wrong listener type", test.addr)
+		}
+	}
+}
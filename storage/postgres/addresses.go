@@ -0,0 +1,78 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"umid/umid"
+)
+
+// TopAddresses returns addresses ordered by the stored confirmed value, the filter fields are optional.
+func (s *postgres) TopAddresses(f umid.AddressFilter, limit int, offset int) ([]*umid.TopAddress2, error) {
+	// the conditions are built dynamically so that the planner can use the (type, value) and (version, value) indexes
+	where := make([]string, 0, 2)
+	args := make([]interface{}, 0, 4)
+
+	if f.Type != "" {
+		args = append(args, f.Type)
+		where = append(where, fmt.Sprintf("type = $%d::address_type", len(args)))
+	}
+
+	if f.Prefix != "" {
+		args = append(args, f.Prefix)
+		where = append(where, fmt.Sprintf("version = convert_prefix_to_version($%d)", len(args)))
+	}
+
+	sql := `select address, type, value, percent, updated_at from address_balance_confirmed`
+
+	if len(where) > 0 {
+		sql += ` where ` + strings.Join(where, ` and `)
+	}
+
+	args = append(args, limit, offset)
+	sql += fmt.Sprintf(` order by value desc, address limit $%d offset $%d`, len(args)-1, len(args))
+
+	rows, err := s.conn.Query(context.Background(), sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]*umid.TopAddress2, 0, limit)
+
+	for rows.Next() {
+		a := &umid.TopAddress2{}
+
+		if err := rows.Scan(&a.Address, &a.Type, &a.Value, &a.Percent, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		res = append(res, a)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
@@ -22,14 +22,17 @@ package postgres
 
 import (
 	"context"
+	"time"
 	"umid/umid"
 )
 
-// Balance ...
-func (s *postgres) Balance(adr []byte) (*umid.Balance, error) {
+// Balance returns the balance at the given time, or the current one if it is nil.
+func (s *postgres) Balance(adr []byte, at *time.Time) (*umid.Balance, error) {
+	const sql = `select * from get_address_balance($1, coalesce($2, now())::timestamptz(0))`
+
 	bal := &umid.Balance{}
 
-	row := s.conn.QueryRow(context.Background(), `select * from get_address_balance($1)`, adr)
+	row := s.conn.QueryRow(context.Background(), sql, adr, at)
 
 	if err := row.Scan(&bal.Confirmed, &bal.Interest, &bal.Unconfirmed, &bal.Composite, &bal.Type); err != nil {
 		return nil, err
@@ -37,3 +40,41 @@ func (s *postgres) Balance(adr []byte) (*umid.Balance, error) {
 
 	return bal, nil
 }
+
+// BalanceHistory returns the balance changes logged before the given log entry, newest first. The interest is
+// the amount accrued since the previous change.
+func (s *postgres) BalanceHistory(adr []byte, before *int64, limit int) ([]*umid.BalanceEntry2, error) {
+	const sql = `select id, tx_height, updated_at, coalesce(delta_value, 0), interest, value, percent, comment
+from (select *, value - coalesce(delta_value, 0) - coalesce(lag(value) over w, 0) as interest
+      from address_balance_confirmed_log
+      where address = $1
+      window w as (order by id)) as l
+where id < coalesce($2, 9223372036854775807)
+order by id desc
+limit $3`
+
+	rows, err := s.conn.Query(context.Background(), sql, adr, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]*umid.BalanceEntry2, 0, limit)
+
+	for rows.Next() {
+		e := &umid.BalanceEntry2{}
+
+		err := rows.Scan(&e.ID, &e.TxHeight, &e.UpdatedAt, &e.Delta, &e.Interest, &e.Balance, &e.Percent, &e.Comment)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, e)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
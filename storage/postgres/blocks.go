@@ -22,7 +22,12 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
+	"umid/umid"
+
+	"github.com/jackc/pgx/v4"
 )
 
 func (s *postgres) LastBlockHeight() (n uint32, err error) {
@@ -32,6 +37,123 @@ func (s *postgres) LastBlockHeight() (n uint32, err error) {
 	return
 }
 
+func (s *postgres) LastBlockHash() (h []byte, err error) {
+	row := s.conn.QueryRow(context.Background(), `select hash from block order by height desc limit 1`)
+	err = row.Scan(&h)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = ErrNotFound
+	}
+
+	return
+}
+
+const blockSelect = `select hash, height, version, prev_block_hash, merkle_root_hash, created_at, tx_count,
+       public_key, synced, confirmed from block`
+
+func (s *postgres) BlockByHash(h []byte) (*umid.Block2, error) {
+	return scanBlock(s.conn.QueryRow(context.Background(), blockSelect+` where hash = $1`, h))
+}
+
+func (s *postgres) BlockByHeight(n uint32) (*umid.Block2, error) {
+	return scanBlock(s.conn.QueryRow(context.Background(), blockSelect+` where height = $1`, n))
+}
+
+func scanBlock(row pgx.Row) (*umid.Block2, error) {
+	blk := &umid.Block2{}
+
+	err := row.Scan(
+		&blk.Hash, &blk.Height, &blk.Version, &blk.PrevBlockHash, &blk.MerkleRootHash, &blk.CreatedAt, &blk.TxCount,
+		&blk.PublicKey, &blk.Synced, &blk.Confirmed,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return blk, nil
+}
+
+func (s *postgres) BlockTxHashes(n uint32) ([][]byte, error) {
+	const sql = `select sha256(substr(b.raw, 168 + i * 150, 150))
+from (select lo_get(height) as raw, tx_count from block where height = $1) b
+         cross join generate_series(0, b.tx_count - 1) i
+order by i`
+
+	rows, err := s.conn.Query(context.Background(), sql, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([][]byte, 0)
+
+	for rows.Next() {
+		var h []byte
+
+		if err := rows.Scan(&h); err != nil {
+			return nil, err
+		}
+
+		res = append(res, h)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
+
+func (s *postgres) BlockTransactions(n uint32, limit, offset int) ([]*umid.Transaction2, error) {
+	const sql = `select p.hash, coalesce(t.height, 0), t.confirmed_at, $1::integer, i, p.version, p.sender,
+       p.recipient, p.value, t.fee_address, t.fee_value,
+       coalesce(t.struct, case when p.prefix is not null then jsonb_build_object('prefix', p.prefix) end)
+from (select lo_get(height) as raw, tx_count from block where height = $1) b
+         cross join generate_series(0, b.tx_count - 1) i
+         cross join lateral parse_transaction(substr(b.raw, 168 + i * 150, 150)) p
+         left join transaction t on t.hash = p.hash
+order by i
+limit $2 offset $3`
+
+	rows, err := s.conn.Query(context.Background(), sql, n, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]*umid.Transaction2, 0, limit)
+
+	for rows.Next() {
+		var confirmedAt *time.Time
+
+		tx := &umid.Transaction2{}
+
+		err := rows.Scan(
+			&tx.Hash, &tx.Height, &confirmedAt, &tx.BlockHeight, &tx.BlockTxIdx, &tx.Version, &tx.Sender,
+			&tx.Recipient, &tx.Value, &tx.FeeAddress, &tx.FeeValue, &tx.Structure,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if confirmedAt != nil {
+			tx.ConfirmedAt = *confirmedAt
+		}
+
+		res = append(res, tx)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
+
 func (s *postgres) AddBlock(b []byte) error {
 	var n int64
 	err := s.conn.QueryRow(context.Background(), `select coalesce(add_block($1), 0)`, b).Scan(&n)
@@ -45,8 +167,16 @@ func (s *postgres) AddBlock(b []byte) error {
 	return err
 }
 
+// BlocksByHeight returns confirmed blocks starting at the height, nodes bootstrapped from a snapshot have none below
+// the snapshot height and return nothing for it.
 func (s *postgres) BlocksByHeight(n uint64) ([][]byte, error) {
-	const sql = `select lo_get(height) from block where height >= $1 and confirmed is true order by height limit 5000`
+	const sql = `select lo_get(height)
+from block
+where height >= $1
+  and confirmed is true
+  and exists(select 1 from block where height = greatest($1, 1))
+order by height
+limit 5000`
 
 	rows, err := s.conn.Query(context.Background(), sql, n)
 	if err != nil {
@@ -0,0 +1,130 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"umid/umid"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const (
+	cleanIntervalSec       = 30
+	defaultMempoolTTL      = 24 * time.Hour
+	defaultMempoolMaxCount = 100_000
+)
+
+type mempoolLimits struct {
+	ttl      time.Duration
+	maxCount int64
+	maxBytes int64
+}
+
+type mempoolCounters struct {
+	confirmed uint64
+	expired   uint64
+	evicted   uint64
+}
+
+// MempoolCleaner ...
+func MempoolCleaner(ctx context.Context, wg *sync.WaitGroup, conn *pgxpool.Pool, lim mempoolLimits,
+	cnt *mempoolCounters) {
+	wg.Add(1)
+	defer wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(cleanIntervalSec * time.Second):
+			if err := clean(ctx, conn, lim, cnt); err != nil {
+				log.Println(err.Error())
+			}
+		}
+	}
+}
+
+func clean(ctx context.Context, conn *pgxpool.Pool, lim mempoolLimits, cnt *mempoolCounters) error {
+	var (
+		ttl                         *time.Duration
+		confirmed, expired, evicted uint64
+	)
+
+	if lim.ttl > 0 {
+		ttl = &lim.ttl
+	}
+
+	row := conn.QueryRow(ctx, `select * from clean_mempool($1, $2, $3)`, ttl, lim.maxCount, lim.maxBytes)
+	if err := row.Scan(&confirmed, &expired, &evicted); err != nil {
+		return err
+	}
+
+	atomic.AddUint64(&cnt.confirmed, confirmed)
+	atomic.AddUint64(&cnt.expired, expired)
+	atomic.AddUint64(&cnt.evicted, evicted)
+
+	return nil
+}
+
+// MempoolCounters ...
+func (s *postgres) MempoolCounters() umid.MempoolCounters {
+	return umid.MempoolCounters{
+		Confirmed: atomic.LoadUint64(&s.counters.confirmed),
+		Expired:   atomic.LoadUint64(&s.counters.expired),
+		Evicted:   atomic.LoadUint64(&s.counters.evicted),
+	}
+}
+
+// loadMempoolLimits reads MEMPOOL_TTL (e.g. 12h, 0 disables expiration), MEMPOOL_MAX_COUNT
+// and MEMPOOL_MAX_BYTES (0 disables the limit).
+func loadMempoolLimits() (lim mempoolLimits, err error) {
+	lim = mempoolLimits{
+		ttl:      defaultMempoolTTL,
+		maxCount: defaultMempoolMaxCount,
+	}
+
+	if val, ok := os.LookupEnv("MEMPOOL_TTL"); ok {
+		if lim.ttl, err = time.ParseDuration(val); err != nil {
+			return lim, err
+		}
+	}
+
+	if val, ok := os.LookupEnv("MEMPOOL_MAX_COUNT"); ok {
+		if lim.maxCount, err = strconv.ParseInt(val, 10, 64); err != nil {
+			return lim, err
+		}
+	}
+
+	if val, ok := os.LookupEnv("MEMPOOL_MAX_BYTES"); ok {
+		if lim.maxBytes, err = strconv.ParseInt(val, 10, 64); err != nil {
+			return lim, err
+		}
+	}
+
+	return lim, nil
+}
@@ -22,15 +22,21 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"log"
 	"sync"
 	"time"
+	"umid/umid"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// purgeMempool removes the transactions of a confirmed block from the mempool.
+const purgeMempool = `delete from mempool where hash in (select hash from transaction where block_height = $1)`
+
 // BlockConfirmer ...
-func BlockConfirmer(ctx context.Context, wg *sync.WaitGroup, conn *pgxpool.Pool) {
+func BlockConfirmer(ctx context.Context, wg *sync.WaitGroup, conn *pgxpool.Pool, validate func([]byte) error) {
 	wg.Add(1)
 	defer wg.Done()
 
@@ -39,12 +45,12 @@ func BlockConfirmer(ctx context.Context, wg *sync.WaitGroup, conn *pgxpool.Pool)
 		case <-ctx.Done():
 			return
 		case <-time.After(time.Second):
-			confirm(ctx, conn)
+			confirm(ctx, conn, validate)
 		}
 	}
 }
 
-func confirm(ctx context.Context, conn *pgxpool.Pool) {
+func confirm(ctx context.Context, conn *pgxpool.Pool, validate func([]byte) error) {
 	var blkHeight int
 
 	select {
@@ -54,12 +60,61 @@ func confirm(ctx context.Context, conn *pgxpool.Pool) {
 		break
 	}
 
+	if validate != nil {
+		if err := validateNextBlock(conn, validate); err != nil {
+			log.Println(err.Error())
+
+			return
+		}
+	}
+
 	err := conn.QueryRow(context.Background(), `select coalesce(confirm_next_block(), 0)`).Scan(&blkHeight)
 	if err != nil {
 		log.Println(err.Error())
 	}
 
 	if blkHeight != 0 {
-		confirm(ctx, conn)
+		if _, err = conn.Exec(context.Background(), purgeMempool, blkHeight); err != nil {
+			log.Println(err.Error())
+		}
+
+		confirm(ctx, conn, validate)
 	}
 }
+
+// validateNextBlock checks the next block against the confirmed state and drops it, together with all blocks
+// after it, if it is not valid.
+func validateNextBlock(conn *pgxpool.Pool, validate func([]byte) error) error {
+	const sql = `select height, lo_get(height) from block where synced is true and confirmed is false
+order by height limit 1`
+
+	var (
+		height int
+		b      []byte
+	)
+
+	err := conn.QueryRow(context.Background(), sql).Scan(&height, &b)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+
+		return err
+	}
+
+	err = validate(b)
+	if err == nil {
+		return nil
+	}
+
+	var vErr *umid.ValidationError
+	if !errors.As(err, &vErr) {
+		return err
+	}
+
+	log.Printf("block %d rejected: %s\n", height, err.Error())
+
+	_, err = conn.Exec(context.Background(), `select drop_unconfirmed_blocks()`)
+
+	return err
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+	"umid/umid"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const (
+	eventChannel   = "umid_events"
+	eventConfirmTx = "confirmedTx"
+)
+
+type eventPayload struct {
+	Kind      string   `json:"kind"`
+	Height    uint32   `json:"height"`
+	Hash      string   `json:"hash"`
+	Addresses []string `json:"addresses"`
+	Prefix    string   `json:"prefix"`
+}
+
+// SetEventBus ...
+func (s *postgres) SetEventBus(bus umid.IEventBus) {
+	s.bus = bus
+}
+
+// EventListener listens to the notifications sent by the database routines on a dedicated connection
+// and publishes them to the event bus.
+func EventListener(ctx context.Context, wg *sync.WaitGroup, pool *pgxpool.Pool, bus umid.IEventBus) {
+	wg.Add(1)
+	defer wg.Done()
+
+	if bus == nil {
+		return
+	}
+
+	// reconnect if the connection is lost
+	for {
+		err := listen(ctx, pool, bus)
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			log.Println(err.Error())
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+func listen(ctx context.Context, pool *pgxpool.Pool, bus umid.IEventBus) error {
+	conn, err := pgx.ConnectConfig(ctx, pool.Config().ConnConfig)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	if _, err = conn.Exec(ctx, `listen `+eventChannel); err != nil {
+		return err
+	}
+
+	// confirmedTx notifications precede the confirmedBlock one and are collected into it
+	txs := make([]umid.EventTx, 0)
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		p := eventPayload{}
+		if err := json.Unmarshal([]byte(n.Payload), &p); err != nil {
+			log.Println(err.Error())
+
+			continue
+		}
+
+		tx := umid.EventTx{Hash: decodeHex(p.Hash), Addresses: make([][]byte, 0, len(p.Addresses)), Prefix: p.Prefix}
+		for _, a := range p.Addresses {
+			tx.Addresses = append(tx.Addresses, decodeHex(a))
+		}
+
+		switch p.Kind {
+		case eventConfirmTx:
+			txs = append(txs, tx)
+		case umid.EventConfirmedBlock:
+			bus.Publish(&umid.Event{Kind: p.Kind, Height: p.Height, Hash: tx.Hash, Txs: txs})
+			txs = make([]umid.EventTx, 0)
+		case umid.EventNewTransaction:
+			bus.Publish(&umid.Event{Kind: p.Kind, Txs: []umid.EventTx{tx}})
+		default:
+			bus.Publish(&umid.Event{Kind: p.Kind, Height: p.Height, Hash: tx.Hash})
+		}
+	}
+}
+
+func decodeHex(s string) []byte {
+	b, _ := hex.DecodeString(s)
+
+	return b
+}
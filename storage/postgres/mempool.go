@@ -38,7 +38,7 @@ type mempool struct {
 func (s *postgres) Mempool() (mem umid.IMempool, err error) {
 	ctx := context.Background()
 
-	tx, err := s.conn.Begin(ctx)
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -55,6 +55,110 @@ func (s *postgres) Mempool() (mem umid.IMempool, err error) {
 	return mem, nil
 }
 
+// PendingSpends sums the values of the basic transactions and the cost of the structures the address has in the
+// mempool.
+func (s *postgres) PendingSpends(adr []byte, createStructureCost uint64) (n uint64, err error) {
+	const sql = `select coalesce(sum(case version when 2 then $2 else value end), 0) from mempool
+where sender = $1 and version in (1, 2)`
+
+	err = s.conn.QueryRow(context.Background(), sql, adr, int64(createStructureCost)).Scan(&n)
+
+	return n, err
+}
+
+// HasNonce checks both the mempool and the confirmed transactions of the address. The confirmed nonce is read
+// from the block: 167 bytes of header, 150 bytes per transaction and the nonce 77 bytes into it.
+func (s *postgres) HasNonce(adr []byte, nonce []byte) (ok bool, err error) {
+	const sql = `select exists(select 1 from mempool where sender = $1 and nonce = $2)
+    or exists(select 1 from transaction
+              where sender = $1
+                and version <> 0
+                and lo_get(block_height, 244 + block_tx_idx * 150, 8) = $2)`
+
+	err = s.conn.QueryRow(context.Background(), sql, adr, nonce).Scan(&ok)
+
+	return ok, err
+}
+
+// MempoolInfo ...
+func (s *postgres) MempoolInfo() (*umid.MempoolInfo2, error) {
+	const sql = `select count(*), coalesce(sum(octet_length(raw)), 0), min(created_at) from mempool`
+
+	inf := &umid.MempoolInfo2{}
+
+	err := s.conn.QueryRow(context.Background(), sql).Scan(&inf.Count, &inf.Bytes, &inf.OldestAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return inf, nil
+}
+
+// MempoolTransactions ...
+func (s *postgres) MempoolTransactions(limit, offset int) ([]*umid.Transaction2, error) {
+	const sql = mempoolTxSelect + ` order by m.priority desc, m.created_at, m.hash limit $1 offset $2`
+
+	rows, err := s.conn.Query(context.Background(), sql, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]*umid.Transaction2, 0, limit)
+
+	for rows.Next() {
+		tx, err := scanMempoolTx(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, tx)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
+
+// MempoolTransaction ...
+func (s *postgres) MempoolTransaction(hash []byte) (*umid.Transaction2, error) {
+	const sql = mempoolTxSelect + ` where m.hash = $1`
+
+	tx, err := scanMempoolTx(s.conn.QueryRow(context.Background(), sql, hash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+const mempoolTxSelect = `select m.hash, m.created_at, p.version, p.sender, p.recipient, p.value,
+       case
+           when p.version in (2, 3) then jsonb_build_object('prefix', p.prefix, 'name', p.name,
+                                                            'profit_percent', p.profit_percent,
+                                                            'fee_percent', p.fee_percent)
+           when p.prefix is not null then jsonb_build_object('prefix', p.prefix)
+           end
+from mempool m,
+     lateral parse_transaction(m.raw) p`
+
+func scanMempoolTx(row pgx.Row) (*umid.Transaction2, error) {
+	tx := &umid.Transaction2{}
+
+	err := row.Scan(&tx.Hash, &tx.CreatedAt, &tx.Version, &tx.Sender, &tx.Recipient, &tx.Value, &tx.Structure)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
 func (m *mempool) Next() bool {
 	ctx := context.Background()
 	row := m.tx.QueryRow(ctx, `fetch next from cur`)
@@ -0,0 +1,92 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"umid/umid"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Peers returns peers seen during the last week that are neither failing nor banned, most recently seen first.
+func (s *postgres) Peers(limit int) ([]*umid.Peer2, error) {
+	const sql = `select url, last_seen_at, failures, banned_until
+from peer
+where last_seen_at > now() - interval '7 days'
+  and failures < 5
+  and (banned_until is null or banned_until < now())
+order by last_seen_at desc
+limit $1`
+
+	rows, err := s.conn.Query(context.Background(), sql, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]*umid.Peer2, 0)
+
+	for rows.Next() {
+		p := &umid.Peer2{}
+
+		if err := rows.Scan(&p.URL, &p.LastSeenAt, &p.Failures, &p.BannedUntil); err != nil {
+			return nil, err
+		}
+
+		res = append(res, p)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
+
+// SavePeers inserts new peers and updates the state of known ones, a missing last seen time keeps the stored one.
+func (s *postgres) SavePeers(peers []*umid.Peer2) error {
+	const sql = `insert into peer (url, last_seen_at, failures, banned_until)
+values ($1, $2, $3, $4)
+on conflict on constraint peer_pk do update
+    set last_seen_at = coalesce(excluded.last_seen_at, peer.last_seen_at),
+        failures     = excluded.failures,
+        banned_until = excluded.banned_until,
+        updated_at   = now()`
+
+	b := &pgx.Batch{}
+
+	for _, p := range peers {
+		b.Queue(sql, p.URL, p.LastSeenAt, p.Failures, p.BannedUntil)
+	}
+
+	res := s.pool.SendBatch(context.Background(), b)
+
+	for range peers {
+		if _, err := res.Exec(); err != nil {
+			_ = res.Close()
+
+			return err
+		}
+	}
+
+	return res.Close()
+}
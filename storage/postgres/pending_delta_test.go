@@ -0,0 +1,145 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/umitop/libumi"
+)
+
+func newTestAddress(prefix string, n byte) []byte {
+	adr := libumi.NewAddress()
+	adr.SetPrefix(prefix)
+	adr.SetPublicKey(append(make([]byte, 31), n))
+
+	return adr
+}
+
+// TestPendingDeltaMatchesConfirmTxBasic checks get_address_pending_delta against the balance changes that
+// confirm_tx__basic makes for the same transactions. It migrates the database in DATABASE_URL, which the GitLab
+// test job provides, and rolls its own rows back.
+func TestPendingDeltaMatchesConfirmTxBasic(t *testing.T) {
+	if os.Getenv("DATABASE_URL") == "" {
+		if os.Getenv("GITLAB_CI") != "" {
+			t.Fatal("DATABASE_URL is required in GitLab CI")
+		}
+
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	conn, err := pgxpool.Connect(ctx, os.Getenv("DATABASE_URL"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	Migrate(ctx, &sync.WaitGroup{}, conn)
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var (
+		alice   = newTestAddress("umi", 1)
+		bob     = newTestAddress("umi", 2)
+		dev     = newTestAddress("zzz", 3)
+		profit  = newTestAddress("zzz", 4)
+		fee     = newTestAddress("zzz", 5)
+		deposit = newTestAddress("zzz", 6)
+		transit = newTestAddress("zzz", 7)
+		version = int32(libumi.Address(dev).Version())
+	)
+
+	// a structure with a 1% fee, the transit address is exempt from it
+	_, err = tx.Exec(ctx, `insert into structure_settings (version, prefix, name, profit_percent, fee_percent,
+dev_address, master_address, profit_address, fee_address, created_at, updated_at, tx_height)
+values ($1, 'zzz', 'test', 100, 100, $2, $2, $3, $4, now(), now(), 0)`, version, dev, profit, fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = tx.Exec(ctx, `insert into structure_address (version, prefix, address, type, created_tx_height, created_at)
+values ($1, 'zzz', $2, 'transit', 0, now())`, version, transit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending := []struct {
+		sender, recipient []byte
+		value             int64
+	}{
+		{alice, deposit, 10_000}, // fee 100, dev and profit get 9900
+		{deposit, bob, 1_000},    // dev and profit lose 1000
+		{alice, profit, 500},     // fee 5, dev gets 495 because it includes profit
+		{alice, transit, 2_000},  // no fee
+	}
+
+	for i, p := range pending {
+		nonce := make([]byte, 8)
+		binary.BigEndian.PutUint64(nonce, uint64(i))
+		hash := sha256.Sum256(append(p.sender, nonce...))
+
+		_, err = tx.Exec(ctx, `insert into mempool (hash, raw, priority, created_at, version, sender, recipient, value,
+nonce) values ($1, $1, 0, now(), 1, $2, $3, $4, $5)`, hash[:], p.sender, p.recipient, p.value, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []struct {
+		name    string
+		address []byte
+		delta   int64
+	}{
+		{"alice", alice, -12_500},
+		{"bob", bob, 1_000},
+		{"deposit", deposit, 8_900},
+		{"transit", transit, 2_000},
+		{"dev", dev, 9_900 - 1_000 + 495 + 2_000},
+		{"profit", profit, 9_900 - 1_000 + 495 + 2_000},
+		{"fee", fee, 105},
+	}
+
+	for _, e := range expected {
+		var got int64
+
+		if err := tx.QueryRow(ctx, `select get_address_pending_delta($1)`, e.address).Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+
+		if got != e.delta {
+			t.Errorf("%s: got %d, want %d", e.name, got, e.delta)
+		}
+	}
+}
@@ -28,14 +28,37 @@ import (
 	"sync"
 	"umid/umid"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
-// ErrNotFound ...
-var ErrNotFound = errors.New("not found")
+// Errors.
+var (
+	ErrNotFound      = umid.ErrNotFound
+	ErrBlockRejected = umid.ErrBlockRejected
+
+	ErrSnapshotVersion  = errors.New("unsupported snapshot version")
+	ErrSnapshotChecksum = errors.New("snapshot checksum mismatch")
+	ErrSnapshotInvalid  = errors.New("invalid snapshot")
+	ErrDatabaseNotEmpty = errors.New("database is not empty")
+	ErrNoSignerCheck    = errors.New("signer validator is not set")
+)
+
+// querier runs read queries either on the pool or inside a transaction, so that the block validator can see the
+// state of a reorganization that is not committed yet.
+type querier interface {
+	Query(context.Context, string, ...interface{}) (pgx.Rows, error)
+	QueryRow(context.Context, string, ...interface{}) pgx.Row
+}
 
 type postgres struct {
-	conn *pgxpool.Pool
+	pool      *pgxpool.Pool
+	conn      querier
+	validator func([]byte) error
+	signer    signerCheck
+	limits    mempoolLimits
+	counters  *mempoolCounters
+	bus       umid.IEventBus
 }
 
 // NewStorage ...
@@ -52,10 +75,27 @@ func NewStorage() umid.IStorage {
 		log.Fatal(err.Error())
 	}
 
-	return &postgres{conn}
+	lim, err := loadMempoolLimits()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	return &postgres{pool: conn, conn: conn, limits: lim, counters: &mempoolCounters{}}
+}
+
+// SetBlockValidator ...
+func (s *postgres) SetBlockValidator(fn func([]byte) error) {
+	s.validator = fn
+}
+
+// SetSignerValidator ...
+func (s *postgres) SetSignerValidator(fn func([]byte, uint32) error) {
+	s.signer = fn
 }
 
 func (s *postgres) Worker(ctx context.Context, wg *sync.WaitGroup) {
-	go Migrate(ctx, wg, s.conn)
-	go BlockConfirmer(ctx, wg, s.conn)
+	go Migrate(ctx, wg, s.pool)
+	go BlockConfirmer(ctx, wg, s.pool, s.validator)
+	go EventListener(ctx, wg, s.pool, s.bus)
+	go MempoolCleaner(ctx, wg, s.pool, s.limits, s.counters)
 }
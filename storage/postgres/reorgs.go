@@ -0,0 +1,247 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log"
+	"umid/umid"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Reorganize replaces the blocks above the height with the branch and records the reorganization in one
+// transaction. Each block of the branch is checked by the validator against the state left by the previous one and
+// confirmed before the next one is added, so an invalid branch leaves the local chain untouched. Basic transactions
+// of the rolled back blocks that are not in the branch go back to the mempool.
+func (s *postgres) Reorganize(height uint32, blocks [][]byte, peer string, validate umid.BranchValidator) error {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	hash, err := recordReorg(ctx, tx, height, peer)
+	if err != nil {
+		return err
+	}
+
+	orphans, err := orphanedTransactions(ctx, tx, height)
+	if err != nil {
+		return err
+	}
+
+	var n int
+
+	if err = tx.QueryRow(ctx, `select rollback_to_height($1)`, height).Scan(&n); err != nil {
+		return err
+	}
+
+	// the validator reads through the transaction to see the branch blocks confirmed so far
+	view := &postgres{pool: s.pool, conn: tx}
+
+	for _, b := range blocks {
+		if err = addBranchBlock(ctx, tx, view, b, validate); err != nil {
+			return err
+		}
+	}
+
+	const requeue = `select add_transaction(t)
+from unnest($1::bytea[]) t
+where not exists(select 1 from transaction where hash = sha256(t))
+  and not exists(select 1 from mempool where hash = sha256(t))`
+
+	if _, err = tx.Exec(ctx, requeue, orphans); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, `select notify_event($1, $2, $3)`, umid.EventReorg, height, hash); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	log.Printf("chain reorganized at block %d, %d blocks rolled back\n", height, n)
+
+	return nil
+}
+
+// recordReorg locks the chain and records the switch from the current tip to the block at the height, the peer
+// is empty when the rollback was requested by the operator.
+func recordReorg(ctx context.Context, tx pgx.Tx, height uint32, peer string) ([]byte, error) {
+	const sql = `insert into reorg (height, hash, old_height, old_hash, peer)
+select b.height, b.hash, t.height, t.hash, nullif($2, '')
+from block b,
+     (select height, hash from block order by height desc limit 1) t
+where b.height = $1
+  and t.height > $1
+returning hash`
+
+	// the tip must not move between recording it and rolling it back
+	if _, err := tx.Exec(ctx, `lock table block in access exclusive mode`); err != nil {
+		return nil, err
+	}
+
+	var hash []byte
+
+	if err := tx.QueryRow(ctx, sql, height, peer).Scan(&hash); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return hash, nil
+}
+
+// orphanedTransactions returns the basic transactions of the blocks above the height.
+func orphanedTransactions(ctx context.Context, tx pgx.Tx, height uint32) ([][]byte, error) {
+	const sql = `select substr(b.raw, 168 + i * 150, 150)
+from (select lo_get(height) as raw, tx_count from block where height > $1) b,
+     generate_series(0, b.tx_count - 1) i
+where get_byte(b.raw, 167 + i * 150) = 1`
+
+	rows, err := tx.Query(ctx, sql, height)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([][]byte, 0)
+
+	for rows.Next() {
+		var t []byte
+
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+
+		res = append(res, t)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
+
+// addBranchBlock adds the block on top of the chain, validates it and confirms it.
+func addBranchBlock(ctx context.Context, tx pgx.Tx, view umid.IStorage, b []byte, validate umid.BranchValidator) error {
+	var height int
+
+	if err := tx.QueryRow(ctx, `select coalesce(add_block($1), 0)`, b).Scan(&height); err != nil {
+		return err
+	}
+
+	if height == 0 {
+		return umid.NewBlockError(ErrBlockRejected)
+	}
+
+	if err := validate(view, b); err != nil {
+		return err
+	}
+
+	var confirmed int
+
+	if err := tx.QueryRow(ctx, `select coalesce(confirm_next_block(), 0)`).Scan(&confirmed); err != nil {
+		return err
+	}
+
+	if confirmed != height {
+		return umid.NewBlockError(ErrBlockRejected)
+	}
+
+	_, err := tx.Exec(ctx, purgeMempool, height)
+
+	return err
+}
+
+// RollbackToHeight reverts the blocks above the height, records it as a reorganization without a peer and returns
+// how many blocks were removed.
+func (s *postgres) RollbackToHeight(height uint32) (n int, err error) {
+	ctx := context.Background()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// nothing is recorded when there are no blocks above the height, rollback_to_height still checks it
+	hash, err := recordReorg(ctx, tx, height, "")
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return 0, err
+	}
+
+	if err = tx.QueryRow(ctx, `select rollback_to_height($1)`, height).Scan(&n); err != nil {
+		return 0, err
+	}
+
+	if hash != nil {
+		if _, err = tx.Exec(ctx, `select notify_event($1, $2, $3)`, umid.EventReorg, height, hash); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// Reorgs returns the latest reorganizations first.
+func (s *postgres) Reorgs(limit int) ([]*umid.Reorg2, error) {
+	const sql = `select height, hash, old_height, old_hash, peer, created_at from reorg order by id desc limit $1`
+
+	rows, err := s.conn.Query(context.Background(), sql, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]*umid.Reorg2, 0)
+
+	for rows.Next() {
+		r := &umid.Reorg2{}
+
+		if err := rows.Scan(&r.Height, &r.Hash, &r.OldHeight, &r.OldHash, &r.Peer, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		res = append(res, r)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
@@ -37,6 +37,19 @@ func Migrations() [][]string {
 		v2(),
 		v3(),
 		v4(),
+		v5(),
+		v6(),
+		v7(),
+		v8(),
+		v9(),
+		v10(),
+		v11(),
+		v12(),
+		v13(),
+		v14(),
+		v15(),
+		v16(),
+		v17(),
 	}
 }
 
@@ -85,10 +98,10 @@ func v1() []string {
 
 func v2() []string {
 	return []string{
-		routines.AddBlock,
+		routines.AddBlockV1,
 		routines.AddGenesis,
-		routines.AddTransaction,
-		routines.ConfirmNextBlock,
+		routines.AddTransactionV1,
+		routines.ConfirmNextBlockV1,
 		routines.ConfirmTxAddStructure,
 		routines.ConfirmTxAddTransitAddress,
 		routines.ConfirmTxBasic,
@@ -99,16 +112,16 @@ func v2() []string {
 		routines.ConfirmTxUpdStructure,
 		routines.ConvertPrefixToVersion,
 		routines.ConvertVersionToPrefix,
-		routines.GetAddressBalance,
-		routines.GetAddressTransactions,
+		routines.GetAddressBalanceV1,
+		routines.GetAddressTransactionsV1,
 		routinesGetDevAddress(),
 		routines.GetStructureBalance,
 		routines.ParseAddress,
 		routines.ParseBlockHeader,
 		routines.ParseTransaction,
-		routines.TruncateBlockchain,
-		routines.UpdAddressBalance,
-		routines.UpdStructureBalance,
+		routines.TruncateBlockchainV1,
+		routines.UpdAddressBalanceV1,
+		routines.UpdStructureBalanceV1,
 		routines.UpdStructureLevel,
 	}
 }
@@ -132,8 +145,130 @@ func v4() []string {
 		tables.TransactionIdxRecipient,
 		tables.TransactionIdxFeeAddress,
 
-		routines.GetAddressTransactions,
+		routines.GetAddressTransactionsV1,
 		routines.GetStructures,
 		routines.GetStructureByPrefix,
 	}
 }
+
+func v5() []string {
+	return []string{
+		routines.DropUnconfirmedBlocks,
+	}
+}
+
+func v6() []string {
+	return []string{
+		tables.MempoolTxColumns,
+		tables.MempoolTxColumnsData,
+		tables.MempoolSenderIdx,
+
+		routines.AddTransaction,
+	}
+}
+
+func v7() []string {
+	return []string{
+		tables.MempoolCreatedIdx,
+
+		routines.CleanMempool,
+	}
+}
+
+func v8() []string {
+	return []string{
+		routines.GetAddressTransactionsV1Drop,
+		routines.GetAddressTransactions,
+	}
+}
+
+func v9() []string {
+	return []string{
+		routines.NotifyEvent,
+		routines.AddBlock,
+		routines.AddTransaction,
+		routines.ConfirmNextBlock,
+	}
+}
+
+func v10() []string {
+	return []string{
+		tables.MempoolRecipientIdx,
+
+		routines.GetAddressPendingDeltaV1,
+		routines.GetAddressBalance,
+	}
+}
+
+func v11() []string {
+	return []string{
+		tables.StructureBalanceLogIdx,
+		tables.StructureAddressIdx,
+	}
+}
+
+func v12() []string {
+	return []string{
+		tables.AddressBalanceConfirmedValueIdx,
+		tables.AddressBalanceConfirmedTypeValueIdx,
+		tables.AddressBalanceConfirmedVersionValueIdx,
+	}
+}
+
+func v13() []string {
+	return []string{
+		tables.NetworkStats,
+		tables.NetworkStatsDaily,
+
+		routines.UpdNetworkStats,
+		routines.UpdNetworkStatsBlock,
+		routines.UpdAddressBalance,
+		routines.UpdStructureBalance,
+		routines.ConfirmNextBlock,
+		routines.RefreshNetworkStats,
+		routines.CallRefreshNetworkStats,
+	}
+}
+
+func v14() []string {
+	return []string{
+		tables.Peer,
+		tables.PeerLastSeenIdx,
+
+		routines.TruncateBlockchain,
+	}
+}
+
+func v15() []string {
+	return []string{
+		sequences.LogID,
+
+		tables.AddressBalanceConfirmedLogID,
+		tables.AddressBalanceConfirmedLogHeightIdx,
+		tables.StructureBalanceLogID,
+		tables.StructurePercentLogID,
+		tables.StructureSettingsLogID,
+		tables.TransactionIdxBlockHeight,
+		tables.Reorg,
+
+		routines.RollbackToHeight,
+	}
+}
+
+func v16() []string {
+	return []string{
+		tables.Snapshot,
+
+		routines.RollbackToHeight,
+	}
+}
+
+func v17() []string {
+	return []string{
+		tables.MempoolSenderVersionIdx,
+		tables.MempoolRecipientVersionIdx,
+		tables.StructureAddressAddressIdx,
+
+		routines.GetAddressPendingDelta,
+	}
+}
@@ -55,6 +55,78 @@ begin
         return blk_height;
     end if;
 
+    if blk_synced is false then -- блок есть в цепочке, но еще не добавлен
+        update block set synced = true where hash = blk_hash;
+        perform notify_event('newBlock', blk_height, blk_hash);
+        --
+        return lo_from_bytea(blk_height, bytes);
+    end if;
+
+    if blk_version = ver_genesis then
+        blk_height = 1;
+    else
+        -- смотрим на последний добавленный блок
+        select hash, height, created_at into lst_blk_hash, lst_blk_height, lst_blk_time
+        from block order by height desc limit 1;
+
+        if lst_blk_time > blk_time then -- новый блок создан ранее чем последний блок в цеопчке
+			-- не добавляем блок
+            return null;
+		end if;
+
+        if blk_prv_hash = lst_blk_hash then -- новый блок ссылается на последний блок в цепочке
+            blk_height := lst_blk_height + 1;
+        else
+            -- не добавляем блок
+            return null;
+        end if;
+    end if;
+
+    insert into block(hash, height, version, prev_block_hash, merkle_root_hash, created_at, tx_count, public_key,synced)
+    values (blk_hash, blk_height, blk_version, blk_prv_hash, blk_merkle, blk_time, blk_tx_cnt, blk_pubkey, true);
+
+    perform notify_event('newBlock', blk_height, blk_hash);
+
+    return lo_from_bytea(blk_height, bytes);
+end
+$$;
+`
+
+// AddBlockV1 is add_block as migration v2 created it, v9 replaces it.
+const AddBlockV1 = `
+create or replace function add_block(bytes bytea)
+    returns integer
+    language plpgsql
+as
+$$
+declare
+    ver_genesis constant integer := 0;
+    --
+    blk_height           integer;
+    blk_synced           boolean;
+    --
+    blk_hash             bytea;
+    blk_version          smallint;
+    blk_prv_hash         bytea;
+    blk_merkle           bytea;
+    blk_time             timestamptz;
+    blk_tx_cnt           integer;
+    blk_pubkey           bytea;
+    --
+    lst_blk_hash         bytea;
+    lst_blk_height       integer;
+    lst_blk_time         timestamptz;
+begin
+    select hash, version, prev_block_hash, merkle_root_hash, created_at, tx_count, public_key
+    into blk_hash, blk_version, blk_prv_hash, blk_merkle, blk_time, blk_tx_cnt, blk_pubkey
+    from parse_block_header(substr(bytes, 1, 167));
+
+    select height, synced into blk_height, blk_synced from block where hash = blk_hash limit 1;
+
+    if blk_synced is true then -- блок есть в цепочке и уже добавлен
+        return blk_height;
+    end if;
+
     if blk_synced is false then -- блок есть в цепочке, но еще не добавлен
         update block set synced = true where hash = blk_hash;
         --
@@ -27,6 +27,32 @@ create or replace function add_transaction(bytes bytea)
     language plpgsql
 as
 $$
+declare
+    tx_hash      bytea;
+    tx_version   smallint;
+    tx_sender    bytea;
+    tx_recipient bytea;
+    tx_value     bigint;
+begin
+    select hash, version, sender, recipient, value
+    into tx_hash, tx_version, tx_sender, tx_recipient, tx_value
+    from parse_transaction(bytes);
+
+    insert into mempool (hash, raw, priority, created_at, version, sender, recipient, value, nonce)
+    values (tx_hash, bytes, 0, now(), tx_version, tx_sender, tx_recipient, tx_value, substr(bytes, 78, 8));
+
+    perform notify_event('newTransaction', null, tx_hash, array [tx_sender, tx_recipient]);
+end
+$$;
+`
+
+// AddTransactionV1 is add_transaction as migration v2 created it, v6 replaces it.
+const AddTransactionV1 = `
+create or replace function add_transaction(bytes bytea)
+    returns void
+    language plpgsql
+as
+$$
 begin
     insert into mempool (hash, raw, priority, created_at) values (sha256(bytes), bytes, 0, now());
 end
@@ -0,0 +1,61 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package routines
+
+// CleanMempool ...
+const CleanMempool = `
+create or replace function clean_mempool(ttl interval,
+                                         max_count bigint,
+                                         max_bytes bigint,
+                                         out confirmed bigint,
+                                         out expired bigint,
+                                         out evicted bigint)
+    language plpgsql
+as
+$$
+begin
+    -- транзакции, которые уже попали в подтвержденный блок
+    delete from mempool m using transaction t where t.hash = m.hash;
+    get diagnostics confirmed = row_count;
+
+    -- устаревшие транзакции
+    delete from mempool where created_at < now() - ttl;
+    get diagnostics expired = row_count;
+
+    -- вытесняем транзакции с наименьшим приоритетом, если превышены лимиты
+    delete from mempool
+    where hash in (
+        select hash
+        from (
+                 select hash,
+                        row_number() over w           as cnt,
+                        sum(octet_length(raw)) over w as size
+                 from mempool
+                 window w as (order by priority desc, created_at, hash
+                              rows between unbounded preceding and current row)
+             ) as a
+        where (max_count > 0 and cnt > max_count)
+           or (max_bytes > 0 and size > max_bytes)
+    );
+    get diagnostics evicted = row_count;
+end
+$$;
+`
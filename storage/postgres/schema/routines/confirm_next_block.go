@@ -27,6 +27,102 @@ create or replace function confirm_next_block()
     language plpgsql
 as
 $$
+declare
+    genesis         constant smallint := 0;
+    basic           constant smallint := 1;
+    add_struct      constant smallint := 2;
+    upd_struct      constant smallint := 3;
+    upd_profit_adr  constant smallint := 4;
+    upd_fee_adr     constant smallint := 5;
+    add_transit_adr constant smallint := 6;
+    del_transit_adr constant smallint := 7;
+    --
+    hdr_length      constant integer  := 167;
+    trx_length      constant integer  := 150;
+    --
+    blk_bytes                bytea;
+    blk_hash                 bytea;
+    blk_height               integer;
+    blk_time                 timestamptz;
+    blk_tx_cnt               integer;
+    --
+    tx_height                integer;
+    tx_bytes                 bytea;
+begin
+    select hash, height, tx_count, created_at
+    into blk_hash, blk_height, blk_tx_cnt, blk_time
+    from block
+    where synced is true
+      and confirmed is false
+    order by height
+    limit 1;
+
+    if blk_height is null then -- все блоки уже подтверждены
+        return null;
+    end if;
+
+    blk_bytes := lo_get(blk_height);
+    blk_bytes := substr(blk_bytes, hdr_length + 1);
+
+    tx_height := setval('tx_height', nextval('tx_height'), false); -- высота последней подтвержденной транзакции
+
+    for blk_tx_idx in 0..(blk_tx_cnt - 1)
+        loop
+            tx_height := tx_height + 1;
+            tx_bytes := substr(blk_bytes, (1 + (blk_tx_idx * trx_length)), trx_length);
+
+            case get_byte(tx_bytes, 0)
+                when basic
+                    then perform confirm_tx__basic(tx_bytes, tx_height, blk_height, blk_tx_idx, blk_time);
+                when add_transit_adr
+                    then perform confirm_tx__add_transit_address(tx_bytes, tx_height, blk_height, blk_tx_idx, blk_time);
+                when del_transit_adr
+                    then perform confirm_tx__del_transit_address(tx_bytes, tx_height, blk_height, blk_tx_idx, blk_time);
+                when upd_profit_adr
+                    then perform confirm_tx__upd_profit_address(tx_bytes, tx_height, blk_height, blk_tx_idx, blk_time);
+                when upd_fee_adr
+                    then perform confirm_tx__upd_fee_address(tx_bytes, tx_height, blk_height, blk_tx_idx, blk_time);
+                when upd_struct
+                    then perform confirm_tx__upd_structure(tx_bytes, tx_height, blk_height, blk_tx_idx, blk_time);
+                when add_struct
+                    then perform confirm_tx__add_structure(tx_bytes, tx_height, blk_height, blk_tx_idx, blk_time);
+                when genesis
+                    then perform confirm_tx__genesis(tx_bytes, tx_height, blk_height, blk_tx_idx, blk_time);
+                else raise exception 'unknown transaction version';
+                end case;
+
+            perform notify_event('confirmedTx', blk_height, t.hash, array [t.sender, t.recipient, t.fee_address],
+                                 t.struct ->> 'prefix')
+            from transaction t
+            where t.hash = sha256(tx_bytes);
+        end loop;
+
+	perform upd_structure_level(blk_height, blk_time);
+
+    perform upd_network_stats_block(blk_height, blk_time, blk_tx_cnt);
+
+    update block set confirmed = true where height = blk_height;
+
+    perform notify_event('confirmedBlock', blk_height, blk_hash);
+
+    perform setval('tx_height', tx_height, false);
+
+    return blk_height;
+exception when others then
+    perform lo_unlink(height) from block where confirmed is false;
+    delete from block where confirmed is false;
+    return null;
+end
+$$;
+`
+
+// ConfirmNextBlockV1 is confirm_next_block as migration v2 created it, v9 and v13 replace it.
+const ConfirmNextBlockV1 = `
+create or replace function confirm_next_block()
+    returns integer
+    language plpgsql
+as
+$$
 declare
     genesis         constant smallint := 0;
     basic           constant smallint := 1;
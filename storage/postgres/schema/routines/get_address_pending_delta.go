@@ -0,0 +1,190 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package routines
+
+// GetAddressPendingDelta ...
+const GetAddressPendingDelta = `
+create or replace function get_address_pending_delta(address bytea)
+    returns bigint
+    language plpgsql
+    stable
+as
+$$
+declare
+    umi_basic constant integer  := x'55A9'::integer;
+    ver_basic constant smallint := 1;
+    --
+    adr_versions       integer[];
+    next_height        integer;
+    delta              bigint;
+begin
+    -- структуры, в которых адрес является dev, profit или fee
+    select coalesce(array_agg(s.version), '{}')
+    into adr_versions
+    from structure_settings s
+    where get_address_pending_delta.address in (s.dev_address, s.profit_address, s.fee_address);
+    --
+    -- высота, которую получит первая подтвержденная транзакция из мемпула
+    select last_value + 1 into next_height from tx_height;
+    --
+    -- балансы меняются по тем же правилам, что и в confirm_tx__basic
+    with pending as (
+        select m.hash, m.sender, m.recipient, m.value
+        from mempool m
+        where m.version = ver_basic
+          and m.sender = get_address_pending_delta.address
+        union
+        select m.hash, m.sender, m.recipient, m.value
+        from mempool m
+        where m.version = ver_basic
+          and m.recipient = get_address_pending_delta.address
+        union
+        select m.hash, m.sender, m.recipient, m.value
+        from mempool m
+        where m.version = ver_basic
+          and ((get_byte(m.sender, 0) << 8) + get_byte(m.sender, 1)) = any (adr_versions)
+        union
+        select m.hash, m.sender, m.recipient, m.value
+        from mempool m
+        where m.version = ver_basic
+          and ((get_byte(m.recipient, 0) << 8) + get_byte(m.recipient, 1)) = any (adr_versions)
+    ),
+         calc as (
+             select p.sender,
+                    p.recipient,
+                    p.value,
+                    ss.dev_address    as s_dev,
+                    ss.profit_address as s_profit,
+                    ss.fee_address    as s_fee,
+                    rs.dev_address    as r_dev,
+                    rs.profit_address as r_profit,
+                    rs.fee_address    as r_fee,
+                    case
+                        when rs.fee_percent > 0 and not exists(
+                                select 1
+                                from structure_address a
+                                where (a.address = p.recipient or a.address = p.sender)
+                                  and a.created_tx_height < next_height
+                                  and (a.deleted_tx_height is null or a.deleted_tx_height > next_height)
+                            )
+                            then ceil((p.value * rs.fee_percent::bigint)::double precision / 10000)::bigint
+                        else 0::bigint
+                        end           as fee
+             from pending p
+                      left join structure_settings ss
+                                on ss.version = (get_byte(p.sender, 0) << 8) + get_byte(p.sender, 1)
+                                    and ss.version <> umi_basic
+                      left join structure_settings rs
+                                on rs.version = (get_byte(p.recipient, 0) << 8) + get_byte(p.recipient, 1)
+                                    and rs.version <> umi_basic
+         )
+    select coalesce(sum(
+                            -- отправитель
+                            - case when c.sender = get_address_pending_delta.address then c.value else 0 end
+                            - case
+                                  when c.sender not in (c.s_dev, c.s_profit, c.s_fee)
+                                      then
+                                          case when c.s_dev = get_address_pending_delta.address
+                                              then c.value else 0 end +
+                                          case when c.s_profit = get_address_pending_delta.address
+                                              then c.value else 0 end
+                                  when c.sender = c.s_profit
+                                      then
+                                          case when c.s_dev = get_address_pending_delta.address
+                                              then c.value else 0 end
+                                  else 0
+                                  end
+                            -- получатель
+                            + case when c.r_fee = get_address_pending_delta.address then c.fee else 0 end
+                            + case
+                                  when c.recipient not in (c.r_dev, c.r_profit, c.r_fee)
+                                      then
+                                          case when c.r_dev = get_address_pending_delta.address
+                                              then c.value - c.fee else 0 end +
+                                          case when c.r_profit = get_address_pending_delta.address
+                                              then c.value - c.fee else 0 end
+                                  when c.recipient = c.r_profit
+                                      then
+                                          case when c.r_dev = get_address_pending_delta.address
+                                              then c.value - c.fee else 0 end
+                                  else 0
+                                  end
+                            + case when c.recipient = get_address_pending_delta.address then c.value - c.fee else 0 end
+                        ), 0)::bigint
+    into delta
+    from calc c;
+    --
+    return delta;
+end
+$$;
+`
+
+// GetAddressPendingDeltaV1 is get_address_pending_delta as migration v10 created it, v17 replaces it.
+const GetAddressPendingDeltaV1 = `
+create or replace function get_address_pending_delta(address bytea)
+    returns bigint
+    language plpgsql
+    stable
+as
+$$
+declare
+    umi_basic constant integer  := x'55A9'::integer;
+    ver_basic constant smallint := 1;
+    --
+    delta              bigint;
+begin
+    -- комиссия считается по тем же правилам, что и в confirm_tx__basic
+    with pending as (
+        select m.sender,
+               m.recipient,
+               m.value,
+               s.fee_address,
+               case
+                   when s.fee_percent > 0 and not exists(
+                           select 1
+                           from structure_address a
+                           where (a.address = m.recipient or a.address = m.sender)
+                             and a.deleted_tx_height is null
+                       )
+                       then ceil((m.value * s.fee_percent::bigint)::double precision / 10000)::bigint
+                   else 0::bigint
+                   end as fee
+        from mempool m
+                 left join structure_settings s
+                           on s.version = (get_byte(m.recipient, 0) << 8) + get_byte(m.recipient, 1)
+                               and s.version <> umi_basic
+        where m.version = ver_basic
+          and (m.sender = get_address_pending_delta.address
+            or m.recipient = get_address_pending_delta.address
+            or s.fee_address = get_address_pending_delta.address)
+    )
+    select coalesce(sum(
+                            case when p.recipient = get_address_pending_delta.address then p.value - p.fee else 0 end +
+                            case when p.fee_address = get_address_pending_delta.address then p.fee else 0 end -
+                            case when p.sender = get_address_pending_delta.address then p.value else 0 end
+                        ), 0)::bigint
+    into delta
+    from pending p;
+    --
+    return delta;
+end
+$$;
+`
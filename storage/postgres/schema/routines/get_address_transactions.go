@@ -20,8 +20,62 @@
 
 package routines
 
+// GetAddressTransactionsV1Drop ...
+const GetAddressTransactionsV1Drop = `
+drop function if exists get_address_transactions(bytea, integer);
+`
+
 // GetAddressTransactions ...
 const GetAddressTransactions = `
+create or replace function get_address_transactions(address_ bytea,
+                                                    limit_ integer default 100,
+                                                    before_ integer default null,
+                                                    after_ integer default null,
+                                                    direction_ text default null,
+                                                    version_ smallint default null,
+                                                    from_ timestamptz default null,
+                                                    to_ timestamptz default null)
+    returns setof transaction
+    language plpgsql
+    stable
+as
+$$
+declare
+    -- after_ листает историю вперед (по возрастанию height), иначе назад
+    ord  constant text := case when after_ is null then 'desc' else 'asc' end;
+    cond constant text := 'height < coalesce($3, 2147483647) and height > coalesce($4, -1)
+        and ($5::smallint is null or version = $5)
+        and confirmed_at >= coalesce($6, ''-infinity'') and confirmed_at < coalesce($7, ''infinity'')';
+    qry           text[] := '{}';
+begin
+    if direction_ is null or direction_ = 'sent' then
+        qry := qry || format('(select * from transaction where sender = $1 and %s order by height %s limit $2)',
+                             cond, ord);
+    end if;
+
+    if direction_ is null or direction_ = 'received' then
+        qry := qry || format('(select * from transaction where recipient = $1 and %s order by height %s limit $2)',
+                             cond, ord);
+    end if;
+
+    if direction_ is null or direction_ = 'fee' then
+        qry := qry || format('(select * from transaction where fee_address = $1 and %s order by height %s limit $2)',
+                             cond, ord);
+    end if;
+
+    if array_length(qry, 1) is null then
+        return;
+    end if;
+
+    return query execute format('select * from (%s) as a order by height %s limit $2',
+                                array_to_string(qry, ' union '), ord)
+        using address_, limit_, before_, after_, version_, from_, to_;
+end
+$$;
+`
+
+// GetAddressTransactionsV1 is get_address_transactions as migrations v2 and v4 created it, v8 drops it.
+const GetAddressTransactionsV1 = `
 create or replace function get_address_transactions(address_ bytea, limit_ integer default 100)
     returns setof transaction
     language sql
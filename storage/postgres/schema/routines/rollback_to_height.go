@@ -0,0 +1,197 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package routines
+
+// RollbackToHeight ...
+const RollbackToHeight = `
+create or replace function rollback_to_height(target integer)
+    returns integer
+    language plpgsql
+as
+$$
+declare
+    upd_profit_adr constant smallint := 4;
+    --
+    blk_cnt        integer;
+    from_day       date;
+    last_tx        integer;
+    adr_versions   integer[];
+    st_versions    integer[];
+begin
+    if target < 1 then
+        raise exception 'invalid height %', target;
+    end if;
+
+    -- журналы узла, загруженного из снимка, начинаются с высоты снимка
+    if target < (select coalesce(max(height), 0) from snapshot) then
+        raise exception 'height % is below the imported snapshot', target;
+    end if;
+
+    -- add_block и confirm_next_block ждут окончания отката
+    lock table block in access exclusive mode;
+
+    select count(*), min((created_at at time zone 'utc')::date)
+    into blk_cnt, from_day
+    from block
+    where height > target;
+
+    if blk_cnt = 0 then
+        return 0;
+    end if;
+
+    -- высота последней транзакции, которая остается подтвержденной
+    select coalesce(max(height), 0) into last_tx from transaction where block_height <= target;
+
+    -- адреса структур
+    update structure_address
+    set deleted_at        = null,
+        deleted_tx_height = null
+    where deleted_tx_height > last_tx;
+
+    -- при первой смене profit-адреса старый адрес становится fee-адресом, возвращаем ему тип profit
+    update structure_address a
+    set type              = 'profit'::address_type,
+        created_at        = s.created_at,
+        created_tx_height = (select min(l.tx_height) from structure_settings_log l where l.version = a.version)
+    from transaction t,
+         structure_settings s
+    where a.type = 'fee'::address_type
+      and a.created_tx_height > last_tx
+      and t.height = a.created_tx_height
+      and t.version = upd_profit_adr
+      and s.version = a.version;
+
+    delete from structure_address where created_tx_height > last_tx;
+
+    -- балансы адресов
+    update address_balance_confirmed b
+    set value      = l.value,
+        percent    = l.percent,
+        type       = l.type,
+        tx_height  = l.tx_height,
+        updated_at = l.updated_at
+    from (select distinct on (address) address, value, percent, type, tx_height, updated_at
+          from address_balance_confirmed_log
+          where address in (select address from address_balance_confirmed where tx_height > last_tx)
+            and tx_height <= last_tx
+          order by address, tx_height desc, id desc) l
+    where b.address = l.address;
+
+    with d as (delete from address_balance_confirmed where tx_height > last_tx returning version)
+    select array_agg(distinct version) into adr_versions from d;
+
+    delete from address_balance_confirmed_log where tx_height > last_tx;
+
+    update structure_stats s
+    set address_count = (select count(*) from address_balance_confirmed b where b.version = s.version)
+    where s.version = any (adr_versions);
+
+    delete from structure_stats where address_count = 0;
+
+    -- балансы структур
+    update structure_balance b
+    set value      = l.value,
+        percent    = l.percent,
+        tx_height  = l.tx_height,
+        updated_at = l.updated_at
+    from (select distinct on (version) version, value, percent, tx_height, updated_at
+          from structure_balance_log
+          where version in (select version from structure_balance where tx_height > last_tx)
+            and tx_height <= last_tx
+          order by version, tx_height desc, id desc) l
+    where b.version = l.version;
+
+    delete from structure_balance where tx_height > last_tx;
+    delete from structure_balance_log where tx_height > last_tx;
+
+    -- уровни структур (журнал ведется по высоте блока)
+    update structure_percent p
+    set level           = l.level,
+        percent         = l.percent,
+        dev_percent     = l.dev_percent,
+        profit_percent  = l.profit_percent,
+        deposit_percent = l.deposit_percent,
+        block_height    = l.block_height,
+        updated_at      = l.updated_at
+    from (select distinct on (version) version, level, percent, dev_percent, profit_percent, deposit_percent,
+                                       block_height, updated_at
+          from structure_percent_log
+          where version in (select version from structure_percent_log where block_height > target)
+            and block_height <= target
+          order by version, block_height desc, id desc) l
+    where p.version = l.version;
+
+    delete from structure_percent p
+    where not exists(select 1 from structure_percent_log l where l.version = p.version and l.block_height <= target);
+
+    delete from structure_percent_log where block_height > target;
+
+    -- настройки структур
+    select array_agg(version) into st_versions from structure_settings where tx_height > last_tx;
+
+    update structure_settings s
+    set name           = l.name,
+        profit_percent = l.profit_percent,
+        fee_percent    = l.fee_percent,
+        dev_address    = l.dev_address,
+        master_address = l.master_address,
+        tx_height      = l.tx_height,
+        updated_at     = l.created_at
+    from (select distinct on (version) version, name, profit_percent, fee_percent, dev_address, master_address,
+                                       tx_height, created_at
+          from structure_settings_log
+          where version = any (st_versions)
+            and tx_height <= last_tx
+          order by version, tx_height desc, id desc) l
+    where s.version = l.version;
+
+    -- смена fee-адреса не пишется в журнал настроек, поэтому profit- и fee-адреса берем из structure_address
+    update structure_settings s
+    set profit_address = p.address,
+        fee_address    = coalesce(f.address, p.address),
+        tx_height      = greatest(s.tx_height, p.created_tx_height, f.created_tx_height),
+        updated_at     = greatest(s.updated_at, p.created_at, f.created_at)
+    from structure_address p
+             left join structure_address f
+                       on f.version = p.version and f.type = 'fee'::address_type and f.deleted_tx_height is null
+    where p.version = s.version
+      and p.type = 'profit'::address_type
+      and p.deleted_tx_height is null
+      and s.version = any (st_versions)
+      and s.tx_height <= last_tx;
+
+    delete from structure_settings where tx_height > last_tx;
+    delete from structure_settings_log where tx_height > last_tx;
+
+    -- транзакции и блоки
+    delete from transaction where block_height > target;
+
+    perform lo_unlink(height) from block where height > target;
+    delete from block where height > target;
+
+    perform setval('tx_height', last_tx, false);
+
+    perform refresh_network_stats(from_day);
+
+    return blk_cnt;
+end
+$$;
+`
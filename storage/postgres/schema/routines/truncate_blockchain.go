@@ -27,6 +27,29 @@ create or replace function truncate_blockchain()
     language plpgsql
 as
 $$
+declare
+    _sql text;
+begin
+    select into _sql string_agg(format('truncate table %s cascade;', tablename), E'\n')
+    from pg_catalog.pg_tables
+    where schemaname = 'public' and tablename not in ('level', 'peer');
+    if _sql is not null then
+        execute _sql;
+    end if;
+    --
+	perform setval('tx_height', 0, false);
+    perform lo_unlink(lo.loid) from (select distinct loid from pg_largeobject) lo;
+end
+$$;
+`
+
+// TruncateBlockchainV1 is truncate_blockchain as migration v2 created it, v14 replaces it.
+const TruncateBlockchainV1 = `
+create or replace function truncate_blockchain()
+    returns void
+    language plpgsql
+as
+$$
 declare
     _sql text;
 begin
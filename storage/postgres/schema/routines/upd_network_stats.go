@@ -0,0 +1,116 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package routines
+
+// UpdNetworkStats ...
+const UpdNetworkStats = `
+create or replace function upd_network_stats(supply_delta bigint,
+                                             structure_delta bigint default 0,
+                                             funded_delta integer default 0)
+    returns void
+    language sql
+as
+$$
+insert into network_stats (id, total_supply, structure_value, funded_addresses)
+values (1, supply_delta, structure_delta, funded_delta)
+on conflict on constraint network_stats_pk do update
+    set total_supply     = network_stats.total_supply + excluded.total_supply,
+        structure_value  = network_stats.structure_value + excluded.structure_value,
+        funded_addresses = network_stats.funded_addresses + excluded.funded_addresses;
+$$;
+`
+
+// UpdNetworkStatsBlock ...
+const UpdNetworkStatsBlock = `
+create or replace function upd_network_stats_block(blk_height integer,
+                                                   blk_time timestamptz,
+                                                   blk_tx_cnt integer)
+    returns void
+    language sql
+as
+$$
+insert into network_stats (id, height, first_block_at, last_block_at, tx_count, structure_count, updated_at)
+values (1, blk_height, blk_time, blk_time, blk_tx_cnt, (select count(*) from structure_settings), now())
+on conflict on constraint network_stats_pk do update
+    set height          = excluded.height,
+        first_block_at  = coalesce(network_stats.first_block_at, excluded.first_block_at),
+        last_block_at   = excluded.last_block_at,
+        tx_count        = network_stats.tx_count + excluded.tx_count,
+        structure_count = excluded.structure_count,
+        updated_at      = excluded.updated_at;
+
+insert into network_stats_daily (day, block_count, tx_count, first_block_at, last_block_at)
+values ((blk_time at time zone 'utc')::date, 1, blk_tx_cnt, blk_time, blk_time)
+on conflict on constraint network_stats_daily_pk do update
+    set block_count    = network_stats_daily.block_count + 1,
+        tx_count       = network_stats_daily.tx_count + excluded.tx_count,
+        first_block_at = least(network_stats_daily.first_block_at, excluded.first_block_at),
+        last_block_at  = greatest(network_stats_daily.last_block_at, excluded.last_block_at);
+$$;
+`
+
+// RefreshNetworkStats ...
+const RefreshNetworkStats = `
+create or replace function refresh_network_stats(from_day date)
+    returns void
+    language sql
+as
+$$
+delete from network_stats_daily where day >= from_day;
+
+insert into network_stats_daily (day, block_count, tx_count, first_block_at, last_block_at)
+select (created_at at time zone 'utc')::date, count(*), sum(tx_count), min(created_at), max(created_at)
+from block
+where confirmed is true
+  and created_at >= from_day::timestamp at time zone 'utc'
+group by 1;
+
+insert into network_stats (id, height, first_block_at, last_block_at, tx_count, total_supply, structure_value,
+                           structure_count, funded_addresses, updated_at)
+select 1,
+       coalesce(b.height, 0),
+       b.first_at,
+       b.last_at,
+       coalesce(b.tx_count, 0),
+       coalesce((select sum(value) from address_balance_confirmed where type <> 'profit'::address_type), 0) -
+       coalesce((select sum(value) from structure_balance), 0),
+       coalesce((select sum(value) from structure_balance), 0),
+       (select count(*) from structure_settings),
+       (select count(*) from address_balance_confirmed where value > 0),
+       now()
+from (select max(height) as height, min(created_at) as first_at, max(created_at) as last_at, sum(tx_count) as tx_count
+      from block
+      where confirmed is true) b
+on conflict on constraint network_stats_pk do update
+    set height           = excluded.height,
+        first_block_at   = excluded.first_block_at,
+        last_block_at    = excluded.last_block_at,
+        tx_count         = excluded.tx_count,
+        total_supply     = excluded.total_supply,
+        structure_value  = excluded.structure_value,
+        structure_count  = excluded.structure_count,
+        funded_addresses = excluded.funded_addresses,
+        updated_at       = excluded.updated_at;
+$$;
+`
+
+// CallRefreshNetworkStats fills the network stats from all confirmed blocks.
+const CallRefreshNetworkStats = `select refresh_network_stats('-infinity'::date)`
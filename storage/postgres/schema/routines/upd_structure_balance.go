@@ -31,6 +31,59 @@ create or replace function upd_structure_balance(version integer,
     language plpgsql
 as
 $$
+declare
+    str_prefix  char(3);
+    cur_value   bigint;
+    cur_percent smallint;
+    new_value   bigint;
+    new_percent smallint;
+    old_value   bigint;
+begin
+    select b.value into old_value from structure_balance b where b.version = upd_structure_balance.version;
+    --
+    select value, percent
+    into cur_value, cur_percent
+    from get_structure_balance(upd_structure_balance.version, upd_structure_balance.epoch);
+    --
+    new_value := cur_value + upd_structure_balance.delta_value;
+    new_percent := cur_percent;
+    --
+    insert into structure_balance(version, prefix, value, percent, tx_height, updated_at)
+    values (upd_structure_balance.version,
+            convert_version_to_prefix(upd_structure_balance.version),
+            new_value,
+            new_percent,
+            upd_structure_balance.tx_height,
+            upd_structure_balance.epoch)
+    on conflict on constraint structure_balance_pk
+        do update set
+            value      = new_value,
+            percent    = new_percent,
+            tx_height  = upd_structure_balance.tx_height,
+            updated_at = upd_structure_balance.epoch
+    returning prefix into str_prefix;
+    --
+    -- баланс структуры входит в баланс dev и уже учтен в балансах депозитов
+    perform upd_network_stats(coalesce(old_value, 0) - new_value, new_value - coalesce(old_value, 0));
+    --
+    insert into structure_balance_log (version, prefix, value, percent, tx_height, updated_at, comment)
+    values (upd_structure_balance.version, str_prefix, new_value, new_percent, upd_structure_balance.tx_height,
+            upd_structure_balance.epoch, upd_structure_balance.comment);
+end
+$$;
+`
+
+// UpdStructureBalanceV1 is upd_structure_balance as migration v2 created it, v13 replaces it.
+const UpdStructureBalanceV1 = `
+create or replace function upd_structure_balance(version integer,
+                                                 delta_value bigint,
+                                                 epoch timestamptz,
+                                                 tx_height integer,
+                                                 comment text default null)
+    returns void
+    language plpgsql
+as
+$$
 declare
     str_prefix  char(3);
     cur_value   bigint;
@@ -44,3 +44,21 @@ const AddressBalanceConfirmedIdx = `
 create index if not exists address_balance_confirmed_idx
     on address_balance_confirmed (version);
 `
+
+// AddressBalanceConfirmedValueIdx ...
+const AddressBalanceConfirmedValueIdx = `
+create index if not exists address_balance_confirmed_value_idx
+    on address_balance_confirmed (value desc);
+`
+
+// AddressBalanceConfirmedTypeValueIdx ...
+const AddressBalanceConfirmedTypeValueIdx = `
+create index if not exists address_balance_confirmed_type_value_idx
+    on address_balance_confirmed (type, value desc);
+`
+
+// AddressBalanceConfirmedVersionValueIdx ...
+const AddressBalanceConfirmedVersionValueIdx = `
+create index if not exists address_balance_confirmed_version_value_idx
+    on address_balance_confirmed (version, value desc);
+`
@@ -41,3 +41,21 @@ const AddressBalanceConfirmedLogIdx = `
 create index if not exists address_balance_confirmed_log_idx
     on address_balance_confirmed_log (address);
 `
+
+// AddressBalanceConfirmedLogID ...
+const AddressBalanceConfirmedLogID = `
+alter table address_balance_confirmed_log add column if not exists id bigint;
+alter table address_balance_confirmed_log alter column id set default nextval('log_id');
+update address_balance_confirmed_log l
+set id = o.id
+from (select ctid, nextval('log_id') as id
+      from (select ctid from address_balance_confirmed_log where id is null order by tx_height, updated_at, ctid) s) o
+where l.ctid = o.ctid;
+alter table address_balance_confirmed_log alter column id set not null;
+`
+
+// AddressBalanceConfirmedLogHeightIdx ...
+const AddressBalanceConfirmedLogHeightIdx = `
+create index if not exists address_balance_confirmed_log_height_idx
+    on address_balance_confirmed_log (tx_height);
+`
@@ -38,3 +38,57 @@ const MempoolIdx = `
 create index if not exists mempool_idx
     on mempool (priority); 
 `
+
+// MempoolTxColumns ...
+const MempoolTxColumns = `
+alter table mempool
+    add column if not exists version   smallint,
+    add column if not exists sender    bytea,
+    add column if not exists recipient bytea,
+    add column if not exists value     bigint,
+    add column if not exists nonce     bytea;
+`
+
+// MempoolTxColumnsData ...
+const MempoolTxColumnsData = `
+update mempool m
+set version   = p.version,
+    sender    = p.sender,
+    recipient = p.recipient,
+    value     = p.value,
+    nonce     = substr(x.raw, 78, 8)
+from mempool x,
+     lateral parse_transaction(x.raw) p
+where m.hash = x.hash
+  and m.version is null;
+`
+
+// MempoolSenderIdx ...
+const MempoolSenderIdx = `
+create index if not exists mempool_sender_idx
+    on mempool (sender, nonce);
+`
+
+// MempoolCreatedIdx ...
+const MempoolCreatedIdx = `
+create index if not exists mempool_created_idx
+    on mempool (created_at);
+`
+
+// MempoolRecipientIdx ...
+const MempoolRecipientIdx = `
+create index if not exists mempool_recipient_idx
+    on mempool (recipient);
+`
+
+// MempoolSenderVersionIdx ...
+const MempoolSenderVersionIdx = `
+create index if not exists mempool_sender_version_idx
+    on mempool (((get_byte(sender, 0) << 8) + get_byte(sender, 1)));
+`
+
+// MempoolRecipientVersionIdx ...
+const MempoolRecipientVersionIdx = `
+create index if not exists mempool_recipient_version_idx
+    on mempool (((get_byte(recipient, 0) << 8) + get_byte(recipient, 1)));
+`
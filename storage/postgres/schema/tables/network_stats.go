@@ -0,0 +1,55 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package tables
+
+// NetworkStats ...
+const NetworkStats = `
+create table if not exists network_stats
+(
+    id               smallint    default 1 not null
+        constraint network_stats_pk
+            primary key,
+    height           integer     default 0 not null,
+    first_block_at   timestamptz,
+    last_block_at    timestamptz,
+    tx_count         bigint      default 0 not null,
+    total_supply     bigint      default 0 not null,
+    structure_value  bigint      default 0 not null,
+    structure_count  integer     default 0 not null,
+    funded_addresses integer     default 0 not null,
+    updated_at       timestamptz default now() not null,
+    check (id = 1)
+);
+`
+
+// NetworkStatsDaily ...
+const NetworkStatsDaily = `
+create table if not exists network_stats_daily
+(
+    day            date        not null
+        constraint network_stats_daily_pk
+            primary key,
+    block_count    integer     not null,
+    tx_count       integer     not null,
+    first_block_at timestamptz not null,
+    last_block_at  timestamptz not null
+);
+`
@@ -34,3 +34,15 @@ create table if not exists structure_address
     deleted_at         timestamp with time zone
 );
 `
+
+// StructureAddressIdx ...
+const StructureAddressIdx = `
+create index if not exists structure_address_idx
+    on structure_address (version);
+`
+
+// StructureAddressAddressIdx ...
+const StructureAddressAddressIdx = `
+create index if not exists structure_address_address_idx
+    on structure_address (address);
+`
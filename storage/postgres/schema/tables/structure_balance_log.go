@@ -34,3 +34,21 @@ create table if not exists structure_balance_log
     check (value >= 0 and percent >= 0)
 );
 `
+
+// StructureBalanceLogIdx ...
+const StructureBalanceLogIdx = `
+create index if not exists structure_balance_log_idx
+    on structure_balance_log (version, tx_height);
+`
+
+// StructureBalanceLogID ...
+const StructureBalanceLogID = `
+alter table structure_balance_log add column if not exists id bigint;
+alter table structure_balance_log alter column id set default nextval('log_id');
+update structure_balance_log l
+set id = o.id
+from (select ctid, nextval('log_id') as id
+      from (select ctid from structure_balance_log where id is null order by tx_height, updated_at, ctid) s) o
+where l.ctid = o.ctid;
+alter table structure_balance_log alter column id set not null;
+`
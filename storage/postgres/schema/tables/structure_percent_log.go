@@ -43,3 +43,15 @@ const StructurePercentLogIdx = `
 create index if not exists structure_percent_log_idx
     on structure_percent_log (version);
 `
+
+// StructurePercentLogID ...
+const StructurePercentLogID = `
+alter table structure_percent_log add column if not exists id bigint;
+alter table structure_percent_log alter column id set default nextval('log_id');
+update structure_percent_log l
+set id = o.id
+from (select ctid, nextval('log_id') as id
+      from (select ctid from structure_percent_log where id is null order by block_height, updated_at, ctid) s) o
+where l.ctid = o.ctid;
+alter table structure_percent_log alter column id set not null;
+`
@@ -45,3 +45,15 @@ const StructureSettingsLogIdx = `
 create index if not exists structure_settings_log_idx
     on structure_settings_log (version);
 `
+
+// StructureSettingsLogID ...
+const StructureSettingsLogID = `
+alter table structure_settings_log add column if not exists id bigint;
+alter table structure_settings_log alter column id set default nextval('log_id');
+update structure_settings_log l
+set id = o.id
+from (select ctid, nextval('log_id') as id
+      from (select ctid from structure_settings_log where id is null order by tx_height, created_at, ctid) s) o
+where l.ctid = o.ctid;
+alter table structure_settings_log alter column id set not null;
+`
@@ -60,3 +60,9 @@ create index if not exists transaction_idx_fee_address
     on transaction (fee_address, height desc)
     where fee_address is not null;
 `
+
+// TransactionIdxBlockHeight ...
+const TransactionIdxBlockHeight = `
+create index if not exists transaction_idx_block_height
+    on transaction (block_height);
+`
@@ -0,0 +1,488 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+	"umid/storage/postgres/schema"
+	"umid/umid"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/umitop/libumi"
+)
+
+// snapshotVersion is the version of the archive layout, the database schema version is checked separately.
+const snapshotVersion = 1
+
+const (
+	snapshotManifest = "manifest.json"
+	snapshotBlock    = "block.bin"
+
+	snapshotManifestMaxSize = 64 << 10
+)
+
+// snapshotTables are the tables that hold the confirmed state, they are exported with COPY in this order. The logs
+// are exported in full because get_address_balance compounds interest over them, and the transactions so that
+// duplicate checks keep working below the snapshot height.
+var snapshotTables = []string{
+	"address_balance_confirmed",
+	"address_balance_confirmed_log",
+	"structure_settings",
+	"structure_settings_log",
+	"structure_address",
+	"structure_balance",
+	"structure_balance_log",
+	"structure_percent",
+	"structure_percent_log",
+	"structure_stats",
+	"transaction",
+	"network_stats",
+	"network_stats_daily",
+}
+
+// signerCheck returns an error if the key may not sign the block at the height.
+type signerCheck func([]byte, uint32) error
+
+type manifest struct {
+	Version   int               `json:"version"`
+	Schema    int               `json:"schema"`
+	Network   string            `json:"network"`
+	Height    uint32            `json:"height"`
+	Hash      string            `json:"hash"`
+	TxHeight  int64             `json:"tx_height"`
+	CreatedAt time.Time         `json:"created_at"`
+	Files     map[string]string `json:"files"`
+}
+
+func newManifest() *manifest {
+	m := &manifest{
+		Version: snapshotVersion,
+		Schema:  len(schema.Migrations()) - 1,
+		Network: "mainnet",
+		Files:   make(map[string]string),
+	}
+
+	if os.Getenv("NETWORK") == "testnet" {
+		m.Network = "testnet"
+	}
+
+	return m
+}
+
+// ExportSnapshot writes the state at the last confirmed block to a gzipped tar archive. The manifest goes first and
+// holds the sha256 of every other entry, its own sha256 is returned as the digest to check the archive against.
+func (s *postgres) ExportSnapshot(w io.Writer) (*umid.Snapshot, error) {
+	ctx := context.Background()
+
+	tmp, err := ioutil.TempDir("", "umid-snapshot")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	m := newManifest()
+
+	blk, err := s.dumpState(ctx, tmp, m)
+	if err != nil {
+		return nil, err
+	}
+
+	man, _ := json.Marshal(m)
+
+	if err := writeArchive(w, tmp, man, blk); err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(man)
+
+	return &umid.Snapshot{Height: m.Height, Hash: blk.Hash(), Digest: digest[:], CreatedAt: m.CreatedAt}, nil
+}
+
+// dumpState copies the state tables into files in dir within a single read only transaction, so they match the
+// returned block.
+func (s *postgres) dumpState(ctx context.Context, dir string, m *manifest) (libumi.Block, error) {
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var blk []byte
+
+	const sql = `select b.height, lo_get(b.height), now(),
+       (select coalesce(max(t.height), 0) from transaction t where t.block_height <= b.height)
+from block b
+where b.confirmed is true
+order by b.height desc
+limit 1`
+
+	err = tx.QueryRow(ctx, sql).Scan(&m.Height, &blk, &m.CreatedAt, &m.TxHeight)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	m.Hash = hex.EncodeToString((libumi.Block)(blk).Hash())
+	m.Files[snapshotBlock] = checksum(blk)
+
+	for _, t := range snapshotTables {
+		f, err := os.Create(fmt.Sprintf("%s/%s", dir, t))
+		if err != nil {
+			return nil, err
+		}
+
+		h := sha256.New()
+		_, err = tx.Conn().PgConn().CopyTo(ctx, io.MultiWriter(f, h), fmt.Sprintf("copy %s to stdout", t))
+
+		if cErr := f.Close(); err == nil {
+			err = cErr
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		m.Files[t] = hex.EncodeToString(h.Sum(nil))
+	}
+
+	return blk, tx.Commit(ctx)
+}
+
+func writeArchive(w io.Writer, dir string, man []byte, blk []byte) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := writeEntry(tw, snapshotManifest, int64(len(man)), bytes.NewReader(man)); err != nil {
+		return err
+	}
+
+	if err := writeEntry(tw, snapshotBlock, int64(len(blk)), bytes.NewReader(blk)); err != nil {
+		return err
+	}
+
+	for _, t := range snapshotTables {
+		if err := writeFile(tw, dir, t); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+func writeFile(tw *tar.Writer, dir, name string) error {
+	f, err := os.Open(fmt.Sprintf("%s/%s", dir, name))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	return writeEntry(tw, name, fi.Size(), f)
+}
+
+func writeEntry(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	hdr := &tar.Header{Name: name, Mode: 0600, Size: size, ModTime: time.Now()}
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	_, err := io.Copy(tw, r)
+
+	return err
+}
+
+// ImportSnapshot loads an archive written by ExportSnapshot into a database that holds at most the genesis block.
+// Nothing is committed unless the manifest matches the digest, every entry matches its checksum and the tip block is
+// signed by an approved key. The state tables are not verified against the block, so the digest has to come from a
+// trusted source.
+func (s *postgres) ImportSnapshot(r io.Reader, digest []byte) (*umid.Snapshot, error) {
+	ctx := context.Background()
+
+	if s.signer == nil {
+		return nil, ErrNoSignerCheck
+	}
+
+	if err := doMigrate(ctx, s.pool); err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(gz)
+
+	m, err := readManifest(tr, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := prepareImport(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	if err := loadEntries(ctx, tx, tr, m, s.signer); err != nil {
+		return nil, err
+	}
+
+	if err := finishImport(ctx, tx, m); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	hash, _ := hex.DecodeString(m.Hash)
+
+	return &umid.Snapshot{Height: m.Height, Hash: hash, Digest: digest, CreatedAt: m.CreatedAt}, nil
+}
+
+// readManifest checks the manifest against the digest before anything in it is used.
+func readManifest(tr *tar.Reader, digest []byte) (*manifest, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	if hdr.Name != snapshotManifest || hdr.Size > snapshotManifestMaxSize {
+		return nil, ErrSnapshotInvalid
+	}
+
+	b, err := ioutil.ReadAll(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum(b) != hex.EncodeToString(digest) {
+		return nil, ErrSnapshotChecksum
+	}
+
+	m := &manifest{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+
+	exp := newManifest()
+
+	if m.Version != exp.Version || m.Schema != exp.Schema || m.Network != exp.Network {
+		return nil, ErrSnapshotVersion
+	}
+
+	if len(m.Files) != len(snapshotTables)+1 || m.Files[snapshotBlock] == "" {
+		return nil, ErrSnapshotInvalid
+	}
+
+	// entry names end up in COPY statements, so only the known tables are accepted
+	for name := range m.Files {
+		if name != snapshotBlock && !isSnapshotTable(name) {
+			return nil, ErrSnapshotInvalid
+		}
+	}
+
+	return m, nil
+}
+
+// prepareImport makes sure the node has not synced anything beyond genesis and clears the state.
+func prepareImport(ctx context.Context, tx pgx.Tx) error {
+	var height uint32
+
+	if err := tx.QueryRow(ctx, `select coalesce(max(height), 0) from block`).Scan(&height); err != nil {
+		return err
+	}
+
+	if height > 1 {
+		return ErrDatabaseNotEmpty
+	}
+
+	_, err := tx.Exec(ctx, `select truncate_blockchain()`)
+
+	return err
+}
+
+// loadEntries loads every entry listed in the manifest, verify checks the key of the tip block. The block has to
+// come first so that it is checked against the manifest before any table is loaded.
+func loadEntries(ctx context.Context, tx pgx.Tx, tr *tar.Reader, m *manifest, verify signerCheck) error {
+	seen := make(map[string]bool)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		sum, ok := m.Files[hdr.Name]
+		if !ok || seen[hdr.Name] || (len(seen) == 0) != (hdr.Name == snapshotBlock) {
+			return ErrSnapshotInvalid
+		}
+
+		seen[hdr.Name] = true
+
+		if hdr.Name == snapshotBlock {
+			err = loadBlock(ctx, tx, tr, m, sum, verify)
+		} else {
+			err = loadTable(ctx, tx, tr, hdr.Name, sum)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(seen) != len(m.Files) {
+		return ErrSnapshotInvalid
+	}
+
+	return nil
+}
+
+func loadBlock(ctx context.Context, tx pgx.Tx, r io.Reader, m *manifest, sum string, verify signerCheck) error {
+	blk, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if checksum(blk) != sum {
+		return ErrSnapshotChecksum
+	}
+
+	if err := libumi.VerifyBlock(blk); err != nil {
+		return umid.NewBlockError(err)
+	}
+
+	if hex.EncodeToString((libumi.Block)(blk).Hash()) != m.Hash {
+		return ErrSnapshotInvalid
+	}
+
+	if err := verify((libumi.Block)(blk).PublicKey(), m.Height); err != nil {
+		return umid.NewBlockError(err)
+	}
+
+	const sql = `insert into block (hash, height, version, prev_block_hash, merkle_root_hash, created_at, tx_count,
+                   public_key, synced, confirmed)
+select hash, $2, version, prev_block_hash, merkle_root_hash, created_at, tx_count, public_key, true, true
+from parse_block_header(substr($1::bytea, 1, 167))`
+
+	if _, err := tx.Exec(ctx, sql, blk, m.Height); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `select lo_from_bytea($1, $2)`, m.Height, blk)
+
+	return err
+}
+
+func loadTable(ctx context.Context, tx pgx.Tx, r io.Reader, table, sum string) error {
+	h := sha256.New()
+
+	sql := fmt.Sprintf("copy %s from stdin", table)
+
+	if _, err := tx.Conn().PgConn().CopyFrom(ctx, io.TeeReader(r, h), sql); err != nil {
+		return err
+	}
+
+	if hex.EncodeToString(h.Sum(nil)) != sum {
+		return ErrSnapshotChecksum
+	}
+
+	return nil
+}
+
+// finishImport restores the transaction and log counters and records the snapshot.
+func finishImport(ctx context.Context, tx pgx.Tx, m *manifest) error {
+	hash, _ := hex.DecodeString(m.Hash)
+
+	batch := &pgx.Batch{}
+	batch.Queue(`select setval('tx_height', $1, false)`, m.TxHeight)
+	batch.Queue(`select setval('log_id', greatest((select max(id) from address_balance_confirmed_log),
+                                    (select max(id) from structure_balance_log),
+                                    (select max(id) from structure_percent_log),
+                                    (select max(id) from structure_settings_log),
+                                    1))`)
+	batch.Queue(`insert into snapshot (height, hash, created_at) values ($1, $2, $3)`, m.Height, hash, m.CreatedAt)
+
+	res := tx.SendBatch(ctx, batch)
+
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := res.Exec(); err != nil {
+			_ = res.Close()
+
+			return err
+		}
+	}
+
+	return res.Close()
+}
+
+func isSnapshotTable(name string) bool {
+	for _, t := range snapshotTables {
+		if t == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checksum(b []byte) string {
+	h := sha256.Sum256(b)
+
+	return hex.EncodeToString(h[:])
+}
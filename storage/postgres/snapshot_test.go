@@ -0,0 +1,208 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"umid/umid"
+
+	"github.com/umitop/libumi"
+)
+
+type entry struct {
+	name string
+	body []byte
+}
+
+func newTar(t *testing.T, entries ...entry) *tar.Reader {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+
+	for _, e := range entries {
+		if err := writeEntry(tw, e.name, int64(len(e.body)), bytes.NewReader(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return tar.NewReader(buf)
+}
+
+func manifestEntry(t *testing.T, m *manifest) entry {
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return entry{snapshotManifest, b}
+}
+
+func validManifest() *manifest {
+	m := newManifest()
+	m.Files[snapshotBlock] = checksum(nil)
+
+	for _, tbl := range snapshotTables {
+		m.Files[tbl] = checksum(nil)
+	}
+
+	return m
+}
+
+func signedBlock() []byte {
+	sec := ed25519.NewKeyFromSeed(make([]byte, 32))
+
+	snd, rcp := libumi.NewAddress(), libumi.NewAddress()
+	snd.SetPublicKey(sec.Public().(ed25519.PublicKey))
+	rcp.SetPublicKey(make([]byte, 32))
+
+	tx := libumi.NewTxBasic()
+	tx.SetSender(snd)
+	tx.SetRecipient(rcp)
+	tx.SetValue(1)
+	libumi.SignTx(tx, sec)
+
+	blk := libumi.NewBlock()
+	blk.SetPreviousBlockHash(bytes.Repeat([]byte{1}, 32))
+	blk.AppendTransaction(tx)
+
+	mrk, _ := libumi.CalculateMerkleRoot(blk)
+	blk.SetMerkleRootHash(mrk)
+	libumi.SignBlock(blk, sec)
+
+	return blk
+}
+
+func TestReadManifest(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*manifest)
+		first  string
+		err    error
+	}{
+		{"valid", func(*manifest) {}, "", nil},
+		{"digest mismatch", func(m *manifest) { m.Height++ }, "", ErrSnapshotChecksum},
+		{"wrong version", func(m *manifest) { m.Version++ }, "", ErrSnapshotVersion},
+		{"wrong schema", func(m *manifest) { m.Schema-- }, "", ErrSnapshotVersion},
+		{"wrong network", func(m *manifest) { m.Network = "testnet" }, "", ErrSnapshotVersion},
+		{"missing table", func(m *manifest) { delete(m.Files, "transaction") }, "", ErrSnapshotInvalid},
+		{"missing block", func(m *manifest) { delete(m.Files, snapshotBlock) }, "", ErrSnapshotInvalid},
+		{"unknown table", func(m *manifest) {
+			delete(m.Files, "transaction")
+			m.Files["reorg; drop table block"] = checksum(nil)
+		}, "", ErrSnapshotInvalid},
+		{"manifest is not first", func(*manifest) {}, snapshotBlock, ErrSnapshotInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := validManifest()
+			digest := sha256.Sum256(manifestEntry(t, m).body)
+
+			// the digest is taken before the change unless the change is the one being checked
+			tt.change(m)
+
+			if tt.err != ErrSnapshotChecksum {
+				digest = sha256.Sum256(manifestEntry(t, m).body)
+			}
+
+			entries := []entry{manifestEntry(t, m)}
+			if tt.first != "" {
+				entries = append([]entry{{tt.first, nil}}, entries...)
+			}
+
+			_, err := readManifest(newTar(t, entries...), digest[:])
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestLoadEntries(t *testing.T) {
+	blk := signedBlock()
+	pub := (libumi.Block)(blk).PublicKey()
+
+	trusted := func(key []byte, _ uint32) error {
+		if !bytes.Equal(key, pub) {
+			return errors.New("untrusted")
+		}
+
+		return nil
+	}
+
+	untrusted := func([]byte, uint32) error {
+		return errors.New("untrusted")
+	}
+
+	tests := []struct {
+		name   string
+		change func(*manifest)
+		entry  entry
+		verify signerCheck
+		err    error
+	}{
+		{"extra entry", func(*manifest) {}, entry{"reorg", nil}, trusted, ErrSnapshotInvalid},
+		{"table before block", func(*manifest) {}, entry{"transaction", nil}, trusted, ErrSnapshotInvalid},
+		{"bad checksum", func(*manifest) {}, entry{snapshotBlock, blk}, trusted, ErrSnapshotChecksum},
+		{"other block", func(m *manifest) {
+			m.Files[snapshotBlock] = checksum(blk)
+			m.Hash = hex.EncodeToString(make([]byte, 32))
+		}, entry{snapshotBlock, blk}, trusted, ErrSnapshotInvalid},
+		{"untrusted signer", func(m *manifest) {
+			m.Files[snapshotBlock] = checksum(blk)
+			m.Hash = hex.EncodeToString((libumi.Block)(blk).Hash())
+		}, entry{snapshotBlock, blk}, untrusted, &umid.ValidationError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := validManifest()
+			tt.change(m)
+
+			// every case fails before the transaction is used
+			err := loadEntries(context.Background(), nil, newTar(t, tt.entry), m, tt.verify)
+
+			var vErr *umid.ValidationError
+			if errors.As(tt.err, &vErr) {
+				if !errors.As(err, &vErr) {
+					t.Errorf("expected validation error, got %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
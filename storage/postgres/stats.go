@@ -0,0 +1,63 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+	"umid/umid"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// NetworkStats reads the summary tables maintained by confirm_next_block. The window is counted in days back from
+// the last confirmed block, so a node that is still syncing reports the rates of the chain it already has.
+func (s *postgres) NetworkStats(days int) (*umid.NetworkStats2, error) {
+	const sql = `select s.height, s.total_supply, s.structure_value, s.structure_count, s.funded_addresses, s.tx_count,
+       s.updated_at, coalesce(d.tx_count, 0), coalesce(d.block_count, 0), d.first_at, d.last_at
+from network_stats s
+         left join lateral (
+    select sum(tx_count)::bigint    as tx_count,
+           sum(block_count)::bigint as block_count,
+           min(first_block_at)      as first_at,
+           max(last_block_at)       as last_at
+    from network_stats_daily
+    where day > (s.last_block_at at time zone 'utc')::date - $1::integer
+    ) d on true
+where s.id = 1`
+
+	st := &umid.NetworkStats2{}
+
+	row := s.conn.QueryRow(context.Background(), sql, days)
+
+	err := row.Scan(&st.Height, &st.TotalSupply, &st.StructureValue, &st.StructureCount, &st.FundedAddresses,
+		&st.TxCount, &st.UpdatedAt, &st.WindowTxCount, &st.WindowBlocks, &st.WindowFirst, &st.WindowLast)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &umid.NetworkStats2{UpdatedAt: time.Now()}, nil
+		}
+
+		return nil, err
+	}
+
+	return st, nil
+}
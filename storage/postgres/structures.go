@@ -77,3 +77,176 @@ func (s *postgres) StructureByPrefix(p string) (*umid.Structure2, error) {
 
 	return st, nil
 }
+
+// StructureHistory returns level and settings changes of the structure in chronological order and its balance
+// changes before the given tx height, newest first.
+func (s *postgres) StructureHistory(p string, before *int32, limit int) (*umid.StructureHistory2, error) {
+	ver, err := s.structureVersion(p)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &umid.StructureHistory2{}
+
+	if res.Levels, err = s.structureLevels(ver); err != nil {
+		return nil, err
+	}
+
+	if res.Settings, err = s.structureSettings(ver); err != nil {
+		return nil, err
+	}
+
+	if res.Balances, err = s.structureBalances(ver, before, limit); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// StructureAddresses returns dev, profit, fee and transit addresses of the structure including deleted ones.
+func (s *postgres) StructureAddresses(p string) ([]*umid.StructureAddress2, error) {
+	const sql = `select address, type, created_tx_height, deleted_tx_height, created_at, deleted_at
+from structure_address
+where version = $1
+order by created_tx_height, type`
+
+	ver, err := s.structureVersion(p)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn.Query(context.Background(), sql, ver)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]*umid.StructureAddress2, 0)
+
+	for rows.Next() {
+		a := &umid.StructureAddress2{}
+
+		err := rows.Scan(&a.Address, &a.Type, &a.CreatedTxHeight, &a.DeletedTxHeight, &a.CreatedAt, &a.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, a)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
+
+func (s *postgres) structureVersion(p string) (ver int32, err error) {
+	row := s.conn.QueryRow(context.Background(), `select version from structure_settings where prefix = $1`, p)
+
+	if err = row.Scan(&ver); errors.Is(err, pgx.ErrNoRows) {
+		err = ErrNotFound
+	}
+
+	return ver, err
+}
+
+func (s *postgres) structureLevels(ver int32) ([]*umid.StructureLevel2, error) {
+	const sql = `select level, percent, dev_percent, profit_percent, deposit_percent, block_height, updated_at, comment
+from structure_percent_log
+where version = $1
+order by updated_at, block_height`
+
+	rows, err := s.conn.Query(context.Background(), sql, ver)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]*umid.StructureLevel2, 0)
+
+	for rows.Next() {
+		l := &umid.StructureLevel2{}
+
+		err := rows.Scan(&l.Level, &l.Percent, &l.DevPercent, &l.ProfitPercent, &l.DepositPercent, &l.BlockHeight,
+			&l.UpdatedAt, &l.Comment)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, l)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
+
+func (s *postgres) structureSettings(ver int32) ([]*umid.StructureSettings2, error) {
+	const sql = `select name, profit_percent, fee_percent, dev_address, master_address, profit_address, fee_address,
+       tx_height, created_at, comment
+from structure_settings_log
+where version = $1
+order by tx_height, created_at`
+
+	rows, err := s.conn.Query(context.Background(), sql, ver)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]*umid.StructureSettings2, 0)
+
+	for rows.Next() {
+		c := &umid.StructureSettings2{}
+
+		err := rows.Scan(&c.Name, &c.ProfitPercent, &c.FeePercent, &c.DevAddress, &c.MasterAddress, &c.ProfitAddress,
+			&c.FeeAddress, &c.TxHeight, &c.CreatedAt, &c.Comment)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, c)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
+
+func (s *postgres) structureBalances(ver int32, before *int32, limit int) ([]*umid.StructureBalance2, error) {
+	const sql = `select value, percent, tx_height, updated_at, comment
+from structure_balance_log
+where version = $1
+  and tx_height < coalesce($2, 2147483647)
+order by tx_height desc, updated_at desc
+limit $3`
+
+	rows, err := s.conn.Query(context.Background(), sql, ver, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([]*umid.StructureBalance2, 0, limit)
+
+	for rows.Next() {
+		b := &umid.StructureBalance2{}
+
+		if err := rows.Scan(&b.Value, &b.Percent, &b.TxHeight, &b.UpdatedAt, &b.Comment); err != nil {
+			return nil, err
+		}
+
+		res = append(res, b)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
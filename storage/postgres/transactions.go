@@ -22,23 +22,60 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"umid/umid"
+
+	"github.com/jackc/pgx/v4"
 )
 
 func (s *postgres) AddTransaction(b []byte) error {
-	_, err := s.conn.Exec(context.Background(), `select add_transaction($1)`, b)
+	_, err := s.pool.Exec(context.Background(), `select add_transaction($1)`, b)
 
 	return err
 }
 
-func (s *postgres) TransactionsByAddress(adr []byte) (txs []*umid.Transaction2, err error) {
-	rows, err := s.conn.Query(context.Background(), `select * from get_address_transactions($1, $2)`, adr, 100)
+func (s *postgres) KnownTransactions(hashes [][]byte) ([][]byte, error) {
+	rows, err := s.conn.Query(context.Background(), `select hash from transaction where hash = any($1)`, hashes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make([][]byte, 0)
+
+	for rows.Next() {
+		var h []byte
+
+		if err := rows.Scan(&h); err != nil {
+			return nil, err
+		}
+
+		res = append(res, h)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	return res, nil
+}
+
+func (s *postgres) TransactionsByAddress(adr []byte, f umid.TxFilter) (txs []*umid.Transaction2, err error) {
+	const sql = `select * from get_address_transactions($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	var dir *string
+	if f.Direction != "" {
+		dir = &f.Direction
+	}
+
+	rows, err := s.conn.Query(context.Background(), sql, adr, f.Limit, f.BeforeHeight, f.AfterHeight, dir, f.Version,
+		f.FromTime, f.ToTime)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	res := make([]*umid.Transaction2, 0, 100)
+	res := make([]*umid.Transaction2, 0, f.Limit)
 
 	for rows.Next() {
 		tx := &umid.Transaction2{}
@@ -60,3 +97,27 @@ func (s *postgres) TransactionsByAddress(adr []byte) (txs []*umid.Transaction2,
 
 	return res, nil
 }
+
+func (s *postgres) TransactionByHash(h []byte) (*umid.Transaction2, error) {
+	const sql = `select t.hash, t.height, t.confirmed_at, t.block_height, t.block_tx_idx, t.version, t.sender,
+       t.recipient, t.value, t.fee_address, t.fee_value, t.struct,
+       (select coalesce(max(height), 0) from block where confirmed is true) - t.block_height + 1
+from transaction t
+where t.hash = $1`
+
+	tx := &umid.Transaction2{}
+
+	err := s.conn.QueryRow(context.Background(), sql, h).Scan(
+		&tx.Hash, &tx.Height, &tx.ConfirmedAt, &tx.BlockHeight, &tx.BlockTxIdx, &tx.Version, &tx.Sender,
+		&tx.Recipient, &tx.Value, &tx.FeeAddress, &tx.FeeValue, &tx.Structure, &tx.Confirmations,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return tx, nil
+}
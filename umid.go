@@ -28,7 +28,9 @@ import (
 	"os/signal"
 	"sync"
 	"umid/blockchain"
+	"umid/events"
 	"umid/jsonrpc"
+	"umid/keystore"
 	"umid/network"
 	"umid/storage"
 )
@@ -37,20 +39,39 @@ func main() {
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
 	log.SetOutput(os.Stdout)
 
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	wg := &sync.WaitGroup{}
 
+	bus := events.NewBus()
 	db := storage.NewStorage()
-	bc := blockchain.NewBlockchain().SetStorage(db)
-	rpc := jsonrpc.NewRPC().SetBlockchain(bc)
+	ks := keystore.NewKeystore()
+	wlt := keystore.NewWallet()
+	bc := blockchain.NewBlockchain().SetStorage(db).SetKeystore(ks)
+	rpc := jsonrpc.NewRPC().SetBlockchain(bc).SetEventBus(bus)
 	net := network.NewNetwork().SetBlockchain(bc)
 	srv := network.NewServer()
 
 	http.HandleFunc("/json-rpc", jsonrpc.CORS(jsonrpc.Filter(rpc.HTTP)))
 	http.HandleFunc("/json-rpc-ws", rpc.WebSocket)
+	http.HandleFunc("/balance-history.csv", rpc.BalanceHistoryCSV)
+
+	if err := ks.SetValidator(bc.VerifyPublicKey); err != nil {
+		log.Fatal(err.Error())
+	}
+
+	db.SetBlockValidator(bc.ValidateBlock)
+	db.SetEventBus(bus)
+
+	wsrv := startWallet(ctx, wg, bc, wlt)
 
 	go db.Worker(ctx, wg)
+	go ks.Worker(ctx, wg)
 	go bc.Worker(ctx, wg)
+	go bc.Generator(ctx, wg)
 	go rpc.Worker(ctx, wg)
 	go net.Worker(ctx, wg)
 	go srv.Serve()
@@ -63,5 +84,38 @@ func main() {
 	cancel()
 	srv.Shutdown()
 
+	if wsrv != nil {
+		wsrv.Shutdown()
+	}
+
 	wg.Wait()
 }
+
+// startWallet serves the wallet methods on their own listener when WALLET_DIR is set, every request must carry
+// WALLET_TOKEN as a bearer token.
+func startWallet(
+	ctx context.Context, wg *sync.WaitGroup, bc *blockchain.Blockchain, wlt *keystore.Wallet,
+) *network.Server {
+	if !wlt.Enabled() {
+		return nil
+	}
+
+	token := os.Getenv("WALLET_TOKEN")
+	if token == "" {
+		log.Fatal("WALLET_TOKEN is required when WALLET_DIR is set")
+	}
+
+	bc.SetWallet(wlt)
+
+	rpc := jsonrpc.NewWalletRPC().SetBlockchain(bc)
+
+	srv, err := network.NewWalletServer(http.HandlerFunc(jsonrpc.Filter(jsonrpc.Bearer(token, rpc.HTTP))))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	go rpc.Worker(ctx, wg)
+	go srv.Serve()
+
+	return srv
+}
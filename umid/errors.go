@@ -0,0 +1,72 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package umid
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors.
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrInvalidTimestamp  = errors.New("invalid timestamp")
+	ErrDuplicateTx       = errors.New("duplicate transaction")
+	ErrTxConfirmed       = errors.New("transaction already confirmed")
+	ErrNonceReplay       = errors.New("nonce already used")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrStructureNotFound = errors.New("structure not found")
+	ErrStructureExists   = errors.New("structure already exists")
+	ErrNotMasterAddress  = errors.New("sender is not a master address")
+	ErrWalletDisabled    = errors.New("wallet disabled")
+	ErrKeyNotFound       = errors.New("key not found")
+	ErrUnknownParent     = errors.New("unknown parent block")
+	ErrBlockRejected     = errors.New("block rejected")
+)
+
+// ValidationError ...
+type ValidationError struct {
+	TxIndex int
+	Err     error
+}
+
+// NewBlockError ...
+func NewBlockError(err error) *ValidationError {
+	return &ValidationError{TxIndex: -1, Err: err}
+}
+
+// NewTxError ...
+func NewTxError(idx int, err error) *ValidationError {
+	return &ValidationError{TxIndex: idx, Err: err}
+}
+
+func (e *ValidationError) Error() string {
+	if e.TxIndex < 0 {
+		return fmt.Sprintf("invalid block: %s", e.Err.Error())
+	}
+
+	return fmt.Sprintf("invalid transaction #%d: %s", e.TxIndex, e.Err.Error())
+}
+
+// Unwrap ...
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2020 UMI
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package umid
+
+// Event kinds.
+const (
+	EventNewBlock       = "newBlock"
+	EventConfirmedBlock = "confirmedBlock"
+	EventNewTransaction = "newTransaction"
+	EventReorg          = "reorg"
+)
+
+// IEventBus ...
+type IEventBus interface {
+	Publish(*Event)
+	Subscribe() (<-chan *Event, func())
+}
+
+// Event ...
+type Event struct {
+	Kind   string
+	Height uint32
+	Hash   []byte
+	Txs    []EventTx
+}
+
+// EventTx ...
+type EventTx struct {
+	Hash      []byte
+	Addresses [][]byte
+	Prefix    string
+}
@@ -22,6 +22,8 @@ package umid
 
 import (
 	"context"
+	"crypto/ed25519"
+	"io"
 	"sync"
 	"time"
 )
@@ -30,27 +32,88 @@ import (
 type IStorage interface {
 	Worker(context.Context, *sync.WaitGroup)
 	Mempool() (IMempool, error)
-	Balance([]byte) (*Balance, error)
+	Balance([]byte, *time.Time) (*Balance, error)
+	BalanceHistory([]byte, *int64, int) ([]*BalanceEntry2, error)
 	StructureByPrefix(string) (*Structure2, error)
 	Structures() ([]*Structure2, error)
-	TransactionsByAddress([]byte) ([]*Transaction2, error)
+	StructureHistory(string, *int32, int) (*StructureHistory2, error)
+	StructureAddresses(string) ([]*StructureAddress2, error)
+	TransactionsByAddress([]byte, TxFilter) ([]*Transaction2, error)
+	TopAddresses(AddressFilter, int, int) ([]*TopAddress2, error)
+	NetworkStats(int) (*NetworkStats2, error)
+	Peers(int) ([]*Peer2, error)
+	SavePeers([]*Peer2) error
+	Reorganize(uint32, [][]byte, string, BranchValidator) error
+	RollbackToHeight(uint32) (int, error)
+	ExportSnapshot(io.Writer) (*Snapshot, error)
+	ImportSnapshot(io.Reader, []byte) (*Snapshot, error)
+	Reorgs(int) ([]*Reorg2, error)
 	LastBlockHeight() (uint32, error)
+	LastBlockHash() ([]byte, error)
+	BlockByHash([]byte) (*Block2, error)
+	BlockByHeight(uint32) (*Block2, error)
+	BlockTxHashes(uint32) ([][]byte, error)
+	BlockTransactions(uint32, int, int) ([]*Transaction2, error)
+	KnownTransactions([][]byte) ([][]byte, error)
+	SetBlockValidator(func([]byte) error)
+	SetSignerValidator(func([]byte, uint32) error)
+	SetEventBus(IEventBus)
 	AddBlock([]byte) error
 	AddTransaction([]byte) error
+	PendingSpends([]byte, uint64) (uint64, error)
+	HasNonce([]byte, []byte) (bool, error)
+	MempoolCounters() MempoolCounters
+	MempoolInfo() (*MempoolInfo2, error)
+	MempoolTransactions(int, int) ([]*Transaction2, error)
+	MempoolTransaction([]byte) (*Transaction2, error)
+	TransactionByHash([]byte) (*Transaction2, error)
 	BlocksByHeight(uint64) ([][]byte, error)
 }
 
+// BranchValidator checks a block of a branch against the state seen through the given storage.
+type BranchValidator func(IStorage, []byte) error
+
 // IBlockchain ...
 type IBlockchain interface {
-	Balance(string) (*Balance, error)
+	Balance(string, BalanceAt) (*Balance, error)
+	BalanceHistory(string, *int64, int) ([]*BalanceEntry, error)
+	ProjectBalance(string, time.Time) (*Projection, error)
 	AddTransaction([]byte) error
 	AddBlock([]byte) error
 	StructureByPrefix(string) (*Structure, error)
 	Structures() ([]*Structure, error)
-	TransactionsByAddress(string) ([]*Transaction, error)
+	StructureHistory(string, *int32, int) (*StructureHistory, error)
+	StructureAddresses(string) ([]*StructureAddress, error)
+	TransactionsByAddress(string, TxFilter) ([]*Transaction, error)
+	TopAddresses(AddressFilter, int, int) ([]*TopAddress, error)
+	NetworkStats(int) (*NetworkStats, error)
+	BuildTransaction(TxDraft) ([]byte, error)
+	SignTransaction([]byte) ([]byte, error)
+	Peers(int) ([]*Peer, error)
+	SavePeers([]*Peer) error
+	Reorganize(uint32, [][]byte, string) error
+	Reorgs(int) ([]*Reorg, error)
 	LastBlockHeight() (uint32, error)
 	BlocksByHeight(uint64) ([][]byte, error)
+	BlockByHeight(uint32) (*Block, error)
+	BlockByHash(string) (*Block, error)
+	BlockTxHashes(uint32) ([]string, error)
+	BlockTransactions(uint32, int, int) ([]*Transaction, error)
 	Mempool() (IMempool, error)
+	MempoolInfo() (*MempoolInfo, error)
+	MempoolTransactions(int, int) ([]*Transaction, error)
+	MempoolTransaction(string) (*Transaction, error)
+	Transaction(string) (*Transaction, error)
+}
+
+// IKeystore ...
+type IKeystore interface {
+	SecretKey() ed25519.PrivateKey
+}
+
+// IWallet ...
+type IWallet interface {
+	SecretKey([]byte) (ed25519.PrivateKey, bool)
 }
 
 // IMempool ...
@@ -60,30 +123,118 @@ type IMempool interface {
 	Close()
 }
 
+// MempoolCounters ...
+type MempoolCounters struct {
+	Confirmed uint64 `json:"confirmed"`
+	Expired   uint64 `json:"expired"`
+	Evicted   uint64 `json:"evicted"`
+}
+
+// MempoolInfo ...
+type MempoolInfo struct {
+	Count    uint64          `json:"count"`
+	Bytes    uint64          `json:"bytes"`
+	OldestAt int64           `json:"oldest_at,omitempty"`
+	Counters MempoolCounters `json:"counters"`
+}
+
+// MempoolInfo2 ...
+type MempoolInfo2 struct {
+	Count    uint64
+	Bytes    uint64
+	OldestAt *time.Time
+}
+
 // TxStruct ...
 type TxStruct struct {
-	Prefix *string `json:"prefix,omitempty"`
+	Prefix        *string `json:"prefix,omitempty"`
+	Name          *string `json:"name,omitempty"`
+	ProfitPercent *uint16 `json:"profit_percent,omitempty"`
+	FeePercent    *uint16 `json:"fee_percent,omitempty"`
+}
+
+// Transaction statuses.
+const (
+	TxStatusConfirmed = "confirmed"
+	TxStatusPending   = "pending"
+)
+
+// Transaction directions relative to an address.
+const (
+	TxDirectionSent     = "sent"
+	TxDirectionReceived = "received"
+	TxDirectionFee      = "fee"
+)
+
+// TxFilter ...
+type TxFilter struct {
+	Limit        int
+	BeforeHeight *int32
+	AfterHeight  *int32
+	Direction    string
+	Version      *int16
+	FromTime     *time.Time
+	ToTime       *time.Time
+}
+
+// TxDraft describes an unsigned transaction, only the fields used by its version are read.
+type TxDraft struct {
+	Version       uint8
+	Sender        string
+	Recipient     string
+	Value         uint64
+	Prefix        string
+	Name          string
+	ProfitPercent uint16
+	FeePercent    uint16
+	Address       string
 }
 
 // Transaction ...
 type Transaction struct {
-	Hash        string    `json:"hash"`
-	ConfirmedAt int64     `json:"confirmed_at,omitempty"`
-	Height      int32     `json:"height,omitempty"`
-	BlockHeight int32     `json:"block_height"`
-	BlockTxIdx  int32     `json:"block_tx_idx"`
-	Version     int16     `json:"version"`
-	Sender      string    `json:"sender"`
-	Recipient   string    `json:"recipient,omitempty"`
-	Value       *int64    `json:"value,omitempty"`
-	FeeAddress  string    `json:"fee_address,omitempty"`
-	FeeValue    *int64    `json:"fee_value,omitempty"`
-	Structure   *TxStruct `json:"structure,omitempty"`
+	Hash          string    `json:"hash"`
+	Status        string    `json:"status,omitempty"`
+	Confirmations uint32    `json:"confirmations,omitempty"`
+	ConfirmedAt   int64     `json:"confirmed_at,omitempty"`
+	CreatedAt     int64     `json:"created_at,omitempty"`
+	Height        int32     `json:"height,omitempty"`
+	BlockHeight   int32     `json:"block_height"`
+	BlockTxIdx    int32     `json:"block_tx_idx"`
+	Version       int16     `json:"version"`
+	Sender        string    `json:"sender"`
+	Recipient     string    `json:"recipient,omitempty"`
+	Value         *int64    `json:"value,omitempty"`
+	FeeAddress    string    `json:"fee_address,omitempty"`
+	FeeValue      *int64    `json:"fee_value,omitempty"`
+	Structure     *TxStruct `json:"structure,omitempty"`
 }
 
 // Block ...
 type Block struct {
-	Hash []byte
+	Hash           string   `json:"hash"`
+	Height         uint32   `json:"height"`
+	Version        int16    `json:"version"`
+	PrevBlockHash  string   `json:"prev_block_hash,omitempty"`
+	MerkleRootHash string   `json:"merkle_root_hash"`
+	Timestamp      int64    `json:"timestamp"`
+	TxCount        int32    `json:"tx_count"`
+	PublicKey      string   `json:"public_key"`
+	Confirmed      bool     `json:"confirmed"`
+	Transactions   []string `json:"transactions,omitempty"`
+}
+
+// Block2 ...
+type Block2 struct {
+	Hash           []byte
+	Height         uint32
+	Version        int16
+	PrevBlockHash  []byte
+	MerkleRootHash []byte
+	CreatedAt      time.Time
+	TxCount        int32
+	PublicKey      []byte
+	Synced         bool
+	Confirmed      bool
 }
 
 // Balance ...
@@ -95,6 +246,141 @@ type Balance struct {
 	Type        string  `json:"type"`
 }
 
+// BalanceAt selects the moment the balance is calculated for, the zero value means now.
+type BalanceAt struct {
+	Time   *time.Time
+	Height *uint32
+}
+
+// Projection ...
+type Projection struct {
+	Value     uint64 `json:"value"`
+	Projected uint64 `json:"projected"`
+	Interest  uint16 `json:"interest"`
+	Until     int64  `json:"until"`
+}
+
+// BalanceEntry ...
+type BalanceEntry struct {
+	ID        int64  `json:"id"`
+	TxHeight  int32  `json:"tx_height"`
+	UpdatedAt int64  `json:"updated_at"`
+	Delta     int64  `json:"delta"`
+	Interest  int64  `json:"interest"`
+	Balance   uint64 `json:"balance"`
+	Percent   uint16 `json:"percent"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// BalanceEntry2 ...
+type BalanceEntry2 struct {
+	ID        int64
+	TxHeight  int32
+	UpdatedAt time.Time
+	Delta     int64
+	Interest  int64
+	Balance   uint64
+	Percent   uint16
+	Comment   *string
+}
+
+// AddressFilter ...
+type AddressFilter struct {
+	Type   string
+	Prefix string
+}
+
+// TopAddress ...
+type TopAddress struct {
+	Address   string `json:"address"`
+	Type      string `json:"type"`
+	Value     uint64 `json:"value"`
+	Percent   uint16 `json:"percent"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// TopAddress2 ...
+type TopAddress2 struct {
+	Address   []byte
+	Type      string
+	Value     uint64
+	Percent   uint16
+	UpdatedAt time.Time
+}
+
+// NetworkStats ...
+type NetworkStats struct {
+	Height           uint32  `json:"height"`
+	TotalSupply      uint64  `json:"total_supply"`
+	StructureValue   uint64  `json:"structure_value"`
+	StructureCount   uint32  `json:"structure_count"`
+	FundedAddresses  uint32  `json:"funded_addresses"`
+	TxCount          uint64  `json:"tx_count"`
+	WindowDays       int     `json:"window_days"`
+	TxPerDay         float64 `json:"tx_per_day"`
+	AvgBlockInterval float64 `json:"avg_block_interval"`
+	UpdatedAt        int64   `json:"updated_at"`
+}
+
+// NetworkStats2 ...
+type NetworkStats2 struct {
+	Height          uint32
+	TotalSupply     uint64
+	StructureValue  uint64
+	StructureCount  uint32
+	FundedAddresses uint32
+	TxCount         uint64
+	UpdatedAt       time.Time
+	WindowTxCount   uint64
+	WindowBlocks    uint64
+	WindowFirst     *time.Time
+	WindowLast      *time.Time
+}
+
+// Peer ...
+type Peer struct {
+	URL         string `json:"url"`
+	LastSeen    int64  `json:"last_seen"`
+	Failures    int    `json:"-"`
+	BannedUntil int64  `json:"-"`
+}
+
+// Peer2 ...
+type Peer2 struct {
+	URL         string
+	LastSeenAt  *time.Time
+	Failures    int32
+	BannedUntil *time.Time
+}
+
+// Reorg ...
+type Reorg struct {
+	Height    uint32 `json:"height"`
+	Hash      string `json:"hash"`
+	OldHeight uint32 `json:"old_height"`
+	OldHash   string `json:"old_hash"`
+	Peer      string `json:"peer,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Reorg2 ...
+type Reorg2 struct {
+	Height    uint32
+	Hash      []byte
+	OldHeight uint32
+	OldHash   []byte
+	Peer      *string
+	CreatedAt time.Time
+}
+
+// Snapshot ...
+type Snapshot struct {
+	Height    uint32
+	Hash      []byte
+	Digest    []byte
+	CreatedAt time.Time
+}
+
 // Structure ...
 type Structure struct {
 	Prefix           string   `json:"prefix"`
@@ -110,20 +396,74 @@ type Structure struct {
 	AddressCount     uint32   `json:"address_count"`
 }
 
+// StructureHistory ...
+type StructureHistory struct {
+	Levels   []*StructureLevel    `json:"levels"`
+	Settings []*StructureSettings `json:"settings"`
+	Balances []*StructureBalance  `json:"balances"`
+}
+
+// StructureLevel ...
+type StructureLevel struct {
+	Level          uint8  `json:"level"`
+	Percent        uint16 `json:"percent"`
+	DevPercent     uint16 `json:"dev_percent"`
+	ProfitPercent  uint16 `json:"profit_percent"`
+	DepositPercent uint16 `json:"deposit_percent"`
+	BlockHeight    int32  `json:"block_height"`
+	UpdatedAt      int64  `json:"updated_at"`
+	Comment        string `json:"comment,omitempty"`
+}
+
+// StructureSettings ...
+type StructureSettings struct {
+	Name          string `json:"name"`
+	ProfitPercent uint16 `json:"profit_percent"`
+	FeePercent    uint16 `json:"fee_percent"`
+	DevAddress    string `json:"dev_address"`
+	MasterAddress string `json:"master_address"`
+	ProfitAddress string `json:"profit_address"`
+	FeeAddress    string `json:"fee_address"`
+	TxHeight      int32  `json:"tx_height"`
+	CreatedAt     int64  `json:"created_at"`
+	Comment       string `json:"comment,omitempty"`
+}
+
+// StructureBalance ...
+type StructureBalance struct {
+	Value     uint64 `json:"value"`
+	Percent   uint16 `json:"percent"`
+	TxHeight  int32  `json:"tx_height"`
+	UpdatedAt int64  `json:"updated_at"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// StructureAddress ...
+type StructureAddress struct {
+	Address         string `json:"address"`
+	Type            string `json:"type"`
+	CreatedTxHeight int32  `json:"created_tx_height"`
+	DeletedTxHeight *int32 `json:"deleted_tx_height,omitempty"`
+	CreatedAt       int64  `json:"created_at"`
+	DeletedAt       *int64 `json:"deleted_at,omitempty"`
+}
+
 // Transaction2 ...
 type Transaction2 struct {
-	Hash        []byte
-	ConfirmedAt time.Time
-	Height      int32
-	BlockHeight int32
-	BlockTxIdx  int32
-	Version     int16
-	Sender      []byte
-	Recipient   []byte
-	Value       *int64
-	FeeAddress  []byte
-	FeeValue    *int64
-	Structure   *TxStruct
+	Hash          []byte
+	ConfirmedAt   time.Time
+	CreatedAt     time.Time
+	Height        int32
+	BlockHeight   int32
+	BlockTxIdx    int32
+	Version       int16
+	Sender        []byte
+	Recipient     []byte
+	Value         *int64
+	FeeAddress    []byte
+	FeeValue      *int64
+	Structure     *TxStruct
+	Confirmations uint32
 }
 
 // Structure2 ...
@@ -140,3 +480,55 @@ type Structure2 struct {
 	Balance          uint64   `json:"balance"`
 	AddressCount     uint32   `json:"address_count"`
 }
+
+// StructureHistory2 ...
+type StructureHistory2 struct {
+	Levels   []*StructureLevel2
+	Settings []*StructureSettings2
+	Balances []*StructureBalance2
+}
+
+// StructureLevel2 ...
+type StructureLevel2 struct {
+	Level          uint8
+	Percent        uint16
+	DevPercent     uint16
+	ProfitPercent  uint16
+	DepositPercent uint16
+	BlockHeight    int32
+	UpdatedAt      time.Time
+	Comment        *string
+}
+
+// StructureSettings2 ...
+type StructureSettings2 struct {
+	Name          string
+	ProfitPercent uint16
+	FeePercent    uint16
+	DevAddress    []byte
+	MasterAddress []byte
+	ProfitAddress []byte
+	FeeAddress    []byte
+	TxHeight      int32
+	CreatedAt     time.Time
+	Comment       *string
+}
+
+// StructureBalance2 ...
+type StructureBalance2 struct {
+	Value     uint64
+	Percent   uint16
+	TxHeight  int32
+	UpdatedAt time.Time
+	Comment   *string
+}
+
+// StructureAddress2 ...
+type StructureAddress2 struct {
+	Address         []byte
+	Type            string
+	CreatedTxHeight int32
+	DeletedTxHeight *int32
+	CreatedAt       time.Time
+	DeletedAt       *time.Time
+}